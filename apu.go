@@ -0,0 +1,310 @@
+package goboy
+
+const (
+	waveRAMStart = 0xFF30  // start of the wave channel's 16 bytes of samples
+	sequencerBit = 1 << 12 // divider bit (DIV bit 4) whose falling edge steps the frame sequencer
+)
+
+// apuReadMasks are ORed into reads of 0xFF10-0xFF2F, write only and unused
+// bits read as 1
+var apuReadMasks = [0x20]uint8{
+	0x80, 0x3F, 0x00, 0xFF, 0xBF, // NR10-NR14
+	0xFF, 0x3F, 0x00, 0xFF, 0xBF, // unused, NR21-NR24
+	0x7F, 0xFF, 0x9F, 0xFF, 0xBF, // NR30-NR34
+	0xFF, 0xFF, 0x00, 0x00, 0xBF, // unused, NR41-NR44
+	0x00, 0x00, 0x70, // NR50-NR52
+	0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, // unused
+}
+
+// apu is the Audio Processing Unit, four channels mixed into stereo
+// see https://gbdev.io/pandocs/Audio.html
+type apu struct {
+	on      bool        // NR52 bit 7, everything but wave RAM is cleared while off
+	regs    [0x20]uint8 // last values written to 0xFF10-0xFF2F, for reads
+	waveRAM [16]uint8   // wave RAM, 0xFF30-0xFF3F
+	step    uint8       // next frame sequencer step, 0-7
+	ch1     square      // square with sweep
+	ch2     square      // square
+	ch3     wave        // wave
+	ch4     noise       // noise
+
+	out audioOutput // resampling and filtering for ReadAudio
+}
+
+// apuTick advances the APU by an M-cycle
+func (gb *GameBoy) apuTick() {
+	a := &gb.apu
+
+	if a.on {
+		a.ch1.step(4)
+		a.ch2.step(4)
+		a.ch3.step(4, &a.waveRAM)
+		a.ch4.step(4)
+	}
+
+	gb.outputAudio(a.mix())
+}
+
+// mix runs the channels through their DACs and NR51's panning, and scales
+// them by NR50's master volume
+func (a *apu) mix() (left, right int) {
+	outputs := [4]struct {
+		dac   bool
+		level uint8
+	}{
+		{a.ch1.dac, a.ch1.output()},
+		{a.ch2.dac, a.ch2.output()},
+		{a.ch3.dac, a.ch3.output()},
+		{a.ch4.dac, a.ch4.output()},
+	}
+
+	nr50, nr51 := a.regs[NR50-NR10], a.regs[NR51-NR10]
+
+	for i, out := range outputs {
+		if !out.dac {
+			continue
+		}
+
+		// a DAC maps 0-15 to an analog level, 0 isn't silence
+		analog := int(out.level)*2 - 15
+
+		if nr51&(0x10<<i) != 0 {
+			left += analog
+		}
+
+		if nr51&(0x01<<i) != 0 {
+			right += analog
+		}
+	}
+
+	left *= int((nr50>>4)&0x07) + 1
+	right *= int(nr50&0x07) + 1
+
+	return left, right
+}
+
+// stepSequencer runs the next step of the frame sequencer, which clocks the
+// length counters at 256Hz, the sweep at 128Hz and the envelopes at 64Hz
+func (gb *GameBoy) stepSequencer() {
+	a := &gb.apu
+
+	if !a.on {
+		return
+	}
+
+	step := a.step
+	a.step = (a.step + 1) & 0x07
+
+	if step%2 == 0 {
+		if a.ch1.length.clock() {
+			a.ch1.on = false
+		}
+
+		if a.ch2.length.clock() {
+			a.ch2.on = false
+		}
+
+		if a.ch3.length.clock() {
+			a.ch3.on = false
+		}
+
+		if a.ch4.length.clock() {
+			a.ch4.on = false
+		}
+	}
+
+	if step == 2 || step == 6 {
+		a.ch1.clockSweep()
+	}
+
+	if step == 7 {
+		a.ch1.env.clock()
+		a.ch2.env.clock()
+		a.ch4.env.clock()
+	}
+}
+
+// lengthControl handles the trigger and length enable bits of an NRx4 write,
+// returning whether the channel was triggered. Length counters count up to size.
+func (a *apu) lengthControl(l *lengthCounter, on *bool, size int, value uint8) (trigger bool) {
+	trigger = value&0x80 != 0
+
+	// when the last frame sequencer step clocked the length counters,
+	// enabling one clocks it an extra time
+	extra := a.step%2 == 1
+
+	wasEnabled := l.enabled
+	l.enabled = value&0x40 != 0
+
+	if extra && !wasEnabled && l.enabled && l.counter > 0 {
+		l.counter--
+
+		if l.counter == 0 && !trigger {
+			*on = false
+		}
+	}
+
+	if trigger && l.counter == 0 {
+		l.counter = size
+
+		if extra && l.enabled {
+			l.counter--
+		}
+	}
+
+	return trigger
+}
+
+// powerAPU handles NR52's power bit
+func (gb *GameBoy) powerAPU(on bool) {
+	a := &gb.apu
+
+	if on == a.on {
+		return
+	}
+
+	a.on = on
+
+	if on {
+		a.step = 0
+		return
+	}
+
+	// every register is cleared, apart from the length counters on DMG
+	a.regs = [len(a.regs)]uint8{}
+	a.ch1 = square{length: lengthCounter{counter: a.ch1.length.counter}}
+	a.ch2 = square{length: lengthCounter{counter: a.ch2.length.counter}}
+	a.ch3 = wave{length: lengthCounter{counter: a.ch3.length.counter}}
+	a.ch4 = noise{length: lengthCounter{counter: a.ch4.length.counter}}
+}
+
+func (gb *GameBoy) readAPU(address uint16) (value byte) {
+	a := &gb.apu
+
+	if address >= waveRAMStart {
+		if a.ch3.on {
+			// while playing, the CPU sees the byte being played instead.
+			// Real DMGs only manage that on the cycle wave RAM is read.
+			return a.waveRAM[a.ch3.position/2]
+		}
+
+		return a.waveRAM[address-waveRAMStart]
+	}
+
+	if address == NR52 {
+		value = apuReadMasks[NR52-NR10]
+
+		for i, on := range []bool{a.ch1.on, a.ch2.on, a.ch3.on, a.ch4.on} {
+			if on {
+				value |= 1 << i
+			}
+		}
+
+		if a.on {
+			value |= 0x80
+		}
+
+		return value
+	}
+
+	return a.regs[address-NR10] | apuReadMasks[address-NR10]
+}
+
+func (gb *GameBoy) writeAPU(address uint16, value byte) {
+	a := &gb.apu
+
+	if address >= waveRAMStart {
+		if a.ch3.on {
+			a.waveRAM[a.ch3.position/2] = value
+			return
+		}
+
+		a.waveRAM[address-waveRAMStart] = value
+		return
+	}
+
+	if address == NR52 {
+		gb.powerAPU(value&0x80 != 0)
+		return
+	}
+
+	if !a.on {
+		// the DMG still lets the length counters be loaded while it is off
+		switch address {
+		case NR11:
+			a.ch1.length.load(64, int(value&0x3F))
+		case NR21:
+			a.ch2.length.load(64, int(value&0x3F))
+		case NR31:
+			a.ch3.length.load(256, int(value))
+		case NR41:
+			a.ch4.length.load(64, int(value&0x3F))
+		}
+
+		return
+	}
+
+	a.regs[address-NR10] = value
+
+	switch address {
+	case NR10:
+		a.ch1.writeSweep(value)
+	case NR11, NR21:
+		s := a.squareChannel(address)
+		s.duty = value >> 6
+		s.length.load(64, int(value&0x3F))
+	case NR12, NR22:
+		s := a.squareChannel(address)
+		s.env.write(value)
+		s.dac = value&0xF8 != 0
+		s.on = s.on && s.dac
+	case NR13, NR23:
+		s := a.squareChannel(address)
+		s.frequency = s.frequency&0x0700 | uint16(value)
+	case NR14, NR24:
+		s := a.squareChannel(address)
+		s.frequency = s.frequency&0x00FF | uint16(value&0x07)<<8
+
+		if a.lengthControl(&s.length, &s.on, 64, value) {
+			s.trigger()
+		}
+	case NR30:
+		a.ch3.dac = value&0x80 != 0
+		a.ch3.on = a.ch3.on && a.ch3.dac
+	case NR31:
+		a.ch3.length.load(256, int(value))
+	case NR32:
+		a.ch3.volume = (value >> 5) & 0x03
+	case NR33:
+		a.ch3.frequency = a.ch3.frequency&0x0700 | uint16(value)
+	case NR34:
+		a.ch3.frequency = a.ch3.frequency&0x00FF | uint16(value&0x07)<<8
+
+		if a.lengthControl(&a.ch3.length, &a.ch3.on, 256, value) {
+			a.ch3.trigger()
+		}
+	case NR41:
+		a.ch4.length.load(64, int(value&0x3F))
+	case NR42:
+		a.ch4.env.write(value)
+		a.ch4.dac = value&0xF8 != 0
+		a.ch4.on = a.ch4.on && a.ch4.dac
+	case NR43:
+		a.ch4.shift = value >> 4
+		a.ch4.narrow = value&0x08 != 0
+		a.ch4.divisor = value & 0x07
+	case NR44:
+		if a.lengthControl(&a.ch4.length, &a.ch4.on, 64, value) {
+			a.ch4.trigger()
+		}
+	}
+}
+
+// squareChannel is the square channel a register belongs to
+func (a *apu) squareChannel(address uint16) (s *square) {
+	if address < NR21 {
+		return &a.ch1
+	}
+
+	return &a.ch2
+}
@@ -0,0 +1,216 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestAPU returns a GameBoy with the APU on, every channel panned to both
+// sides at full volume
+func newTestAPU() (gb *GameBoy) {
+	gb = &GameBoy{}
+	gb.WriteMemory(NR52, 0x80)
+	gb.WriteMemory(NR50, 0x77)
+	gb.WriteMemory(NR51, 0xFF)
+
+	return gb
+}
+
+// steps runs the frame sequencer n steps, the APU runs along with it
+func steps(gb *GameBoy, n int) {
+	ticks(gb, n*sequencerBit*2/4)
+}
+
+func TestAPUPower(t *testing.T) {
+	gb := &GameBoy{}
+
+	assert.Equal(t, uint8(0x70), gb.ReadMemory(NR52))
+
+	// ignored while off
+	gb.WriteMemory(NR12, 0xF3)
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(NR12))
+
+	gb.WriteMemory(NR52, 0x80)
+	assert.Equal(t, uint8(0xF0), gb.ReadMemory(NR52))
+
+	// unused and write only bits read as 1
+	gb.WriteMemory(NR10, 0x00)
+	assert.Equal(t, uint8(0x80), gb.ReadMemory(NR10))
+	gb.WriteMemory(NR11, 0x80)
+	assert.Equal(t, uint8(0xBF), gb.ReadMemory(NR11))
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(NR13))
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0xFF27))
+
+	gb.WriteMemory(NR12, 0xF3)
+	gb.WriteMemory(NR14, 0x80)
+	assert.Equal(t, uint8(0xF1), gb.ReadMemory(NR52), "channel 1 triggered")
+
+	// turning it off clears everything but wave RAM
+	gb.WriteMemory(waveRAMStart, 0x12)
+	gb.WriteMemory(NR52, 0x00)
+	assert.Equal(t, uint8(0x70), gb.ReadMemory(NR52))
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(NR12))
+	assert.Equal(t, uint8(0x12), gb.ReadMemory(waveRAMStart))
+}
+
+func TestLengthCounter(t *testing.T) {
+	gb := newTestAPU()
+
+	gb.WriteMemory(NR22, 0xF0)
+	gb.WriteMemory(NR21, 0x3C) // 4 steps
+	gb.WriteMemory(NR24, 0xC0)
+	assert.Equal(t, uint8(0xF2), gb.ReadMemory(NR52))
+
+	// length is clocked every other step
+	steps(gb, 6)
+	assert.Equal(t, uint8(0xF2), gb.ReadMemory(NR52))
+
+	steps(gb, 1)
+	assert.Equal(t, uint8(0xF0), gb.ReadMemory(NR52))
+
+	// without the length enabled it plays on
+	gb.WriteMemory(NR24, 0x80)
+	steps(gb, 200)
+	assert.Equal(t, uint8(0xF2), gb.ReadMemory(NR52))
+
+	// triggering with the counter at 0 loads the full length
+	steps(gb, 1)
+	gb.WriteMemory(NR30, 0x80)
+	gb.WriteMemory(NR34, 0xC0)
+	assert.Equal(t, 256, gb.apu.ch3.length.counter)
+
+	// less one when the last step clocked length, enabling it clocks it again
+	gb.WriteMemory(NR30, 0x00)
+	gb.WriteMemory(NR34, 0x00)
+	gb.apu.ch3.length.counter = 0
+	steps(gb, 1)
+	gb.WriteMemory(NR30, 0x80)
+	gb.WriteMemory(NR34, 0xC0)
+	assert.Equal(t, 255, gb.apu.ch3.length.counter)
+}
+
+func TestDAC(t *testing.T) {
+	gb := newTestAPU()
+
+	// triggering with the DAC off does nothing
+	gb.WriteMemory(NR42, 0x00)
+	gb.WriteMemory(NR44, 0x80)
+	assert.Equal(t, uint8(0xF0), gb.ReadMemory(NR52))
+
+	gb.WriteMemory(NR42, 0x08)
+	gb.WriteMemory(NR44, 0x80)
+	assert.Equal(t, uint8(0xF8), gb.ReadMemory(NR52))
+
+	// turning the DAC off stops the channel
+	gb.WriteMemory(NR42, 0x00)
+	assert.Equal(t, uint8(0xF0), gb.ReadMemory(NR52))
+}
+
+func TestEnvelope(t *testing.T) {
+	gb := newTestAPU()
+
+	gb.WriteMemory(NR12, 0xA1) // 10, down every step
+	gb.WriteMemory(NR14, 0x80)
+	assert.Equal(t, uint8(10), gb.apu.ch1.env.volume)
+
+	// envelopes are clocked on step 7 of 8
+	steps(gb, 8)
+	assert.Equal(t, uint8(9), gb.apu.ch1.env.volume)
+
+	steps(gb, 8*20)
+	assert.Equal(t, uint8(0), gb.apu.ch1.env.volume)
+
+	gb.WriteMemory(NR12, 0xE9) // 14, up every step
+	gb.WriteMemory(NR14, 0x80)
+	steps(gb, 8*5)
+	assert.Equal(t, uint8(15), gb.apu.ch1.env.volume)
+
+	// a period set after triggering takes over on the next envelope step
+	gb.WriteMemory(NR12, 0xA0) // 10, held
+	gb.WriteMemory(NR14, 0x80)
+	gb.WriteMemory(NR12, 0xA1) // down every step
+	steps(gb, 8)
+	assert.Equal(t, uint8(9), gb.apu.ch1.env.volume)
+}
+
+func TestSweep(t *testing.T) {
+	gb := newTestAPU()
+
+	gb.WriteMemory(NR12, 0xF0)
+	gb.WriteMemory(NR10, 0x11) // every sweep step, +f/2
+	gb.WriteMemory(NR13, 0x00)
+	gb.WriteMemory(NR14, 0x84) // 0x400
+	assert.Equal(t, uint8(0xF1), gb.ReadMemory(NR52))
+
+	// the sweep is clocked on steps 2 and 6, 0x400 + 0x200 = 0x600
+	steps(gb, 3)
+	assert.Equal(t, uint16(0x600), gb.apu.ch1.frequency)
+
+	// 0x600 + 0x300 overflows
+	steps(gb, 4)
+	assert.Equal(t, uint8(0xF0), gb.ReadMemory(NR52))
+
+	// the check on trigger catches it straight away
+	gb.WriteMemory(NR13, 0xFF)
+	gb.WriteMemory(NR14, 0x87)
+	assert.Equal(t, uint8(0xF0), gb.ReadMemory(NR52))
+
+	// subtracting then switching back to addition stops the channel
+	gb.WriteMemory(NR10, 0x19)
+	gb.WriteMemory(NR14, 0x84)
+	assert.Equal(t, uint8(0xF1), gb.ReadMemory(NR52))
+	gb.WriteMemory(NR10, 0x11)
+	assert.Equal(t, uint8(0xF0), gb.ReadMemory(NR52))
+}
+
+func TestNoiseLFSR(t *testing.T) {
+	var n noise
+
+	n.lfsr = 0x7FFF
+	n.timer = 1
+	n.step(8)
+	assert.Equal(t, uint16(0x3FFF), n.lfsr)
+
+	// the 15 bit register repeats every 32767 shifts
+	n.lfsr = 0x7FFF
+	for i := 0; i < 32767; i++ {
+		n.timer = 1
+		n.step(8)
+	}
+	assert.Equal(t, uint16(0x7FFF), n.lfsr)
+
+	// the 7 bit one every 127
+	n.narrow = true
+	n.lfsr = 0x7FFF
+	for i := 0; i < 127; i++ {
+		n.timer = 1
+		n.step(8)
+	}
+	assert.Equal(t, uint16(0x7FFF)&0x7F, n.lfsr&0x7F)
+}
+
+func TestWaveChannel(t *testing.T) {
+	gb := newTestAPU()
+
+	for i := uint16(0); i < 16; i++ {
+		gb.WriteMemory(waveRAMStart+i, uint8(i<<4|i))
+	}
+
+	gb.WriteMemory(NR30, 0x80)
+	gb.WriteMemory(NR32, 0x20) // 100%
+	gb.WriteMemory(NR33, 0x00)
+	gb.WriteMemory(NR34, 0x87) // 0x700, a sample every 512 T-cycles
+	assert.Equal(t, uint8(0xF4), gb.ReadMemory(NR52))
+
+	ticks(gb, 128*3)
+	assert.Equal(t, uint8(3), gb.apu.ch3.position)
+	assert.Equal(t, uint8(1), gb.apu.ch3.output())
+
+	gb.WriteMemory(NR32, 0x40) // 50%
+	ticks(gb, 128*20)
+	assert.Equal(t, uint8(11)>>1, gb.apu.ch3.output())
+
+	// while playing, wave RAM reads the byte being played
+	assert.Equal(t, uint8(0xBB), gb.ReadMemory(waveRAMStart))
+}
@@ -0,0 +1,206 @@
+package goboy
+
+import "math"
+
+// AudioQuality trades the accuracy of ReadAudio's samples for speed
+type AudioQuality uint8
+
+const (
+	AudioBandLimited AudioQuality = iota // band-limited synthesis without aliasing, the default
+	AudioAveraged                        // averages the M-cycles in each sample, cheaper but high notes alias
+)
+
+const (
+	DefaultSampleRate = 44100 // audio sample rate used when SampleRate is 0
+
+	audioBufferFrames = 1 << 15 // stereo samples kept for ReadAudio, newer ones are dropped when full
+	mixerScale        = 32      // from the mixer's ±480 to int16, with room for the high-pass filter's overshoot
+
+	blipTaps   = 32  // band-limited step width in output samples, also its latency
+	blipPhases = 64  // positions between output samples the steps are precomputed for
+	blipSize   = 64  // output samples of steps buffered, a power of 2 above blipTaps
+	blipCutoff = 0.4 // cutoff of the steps, in cycles per output sample
+)
+
+// highPassCharges are how much of its charge the capacitor on the audio
+// output keeps each T-cycle, by model. It blocks DC, so a DAC that is on but
+// not playing anything fades to silence like it does on real hardware.
+// see https://gbdev.io/pandocs/Audio_details.html#obscure-behavior
+var highPassCharges = map[Model]float64{
+	ModelDMG:  0.999958,
+	ModelDMG0: 0.999958,
+	ModelMGB:  0.998943,
+	ModelSGB:  0.999958,
+	ModelSGB2: 0.998943,
+}
+
+// blipKernel holds the impulse response that band-limits a step in the
+// mixer's output, for each position the step can have between two samples.
+// Summing it up gives the step with everything above blipCutoff taken out.
+var blipKernel = newBlipKernel()
+
+// newBlipKernel builds blipKernel from a Blackman windowed sinc
+func newBlipKernel() (kernel *[blipPhases][blipTaps]float64) {
+	kernel = &[blipPhases][blipTaps]float64{}
+
+	for phase := range kernel {
+		var sum float64
+
+		for tap := range kernel[phase] {
+			// distance from the center of the step, in output samples
+			x := float64(tap+1) - float64(phase)/blipPhases - blipTaps/2
+
+			sinc := 2 * blipCutoff
+			if x != 0 {
+				sinc = math.Sin(2*math.Pi*blipCutoff*x) / (math.Pi * x)
+			}
+
+			window := 0.42 + 0.5*math.Cos(2*math.Pi*x/blipTaps) + 0.08*math.Cos(4*math.Pi*x/blipTaps)
+
+			kernel[phase][tap] = sinc * window
+			sum += kernel[phase][tap]
+		}
+
+		// every step ends up exactly as tall as it started
+		for tap := range kernel[phase] {
+			kernel[phase][tap] /= sum
+		}
+	}
+
+	return kernel
+}
+
+// audioOutput turns the mixer's output into samples at SampleRate
+type audioOutput struct {
+	clock int // time since the last sample, the next is due when it reaches ClockSpeed
+
+	// AudioAveraged
+	sum   [2]int // mixer output summed since the last sample
+	count int    // M-cycles summed into sum
+
+	// AudioBandLimited
+	steps    [2][blipSize]float64 // band-limited changes in level, by sample in a ring
+	level    [2]int               // mixer output as of the last change
+	integral [2]float64           // running sum of steps, the band-limited level
+	next     int                  // ring index of the next sample
+
+	capacitor   [2]float64 // charge on the high-pass filter's capacitors
+	chargeRate  int        // sample rate charge was calculated for
+	chargeModel Model      // model charge was calculated for
+	charge      float64    // charge the capacitors keep from one sample to the next
+
+	samples []int16 // interleaved stereo samples waiting for ReadAudio
+}
+
+// ReadAudio moves buffered audio into buf as interleaved left and right
+// samples at SampleRate, returning how many values it wrote. Reading often
+// keeps latency down, audio that isn't read is dropped once the buffer fills.
+func (gb *GameBoy) ReadAudio(buf []int16) (n int) {
+	o := &gb.apu.out
+
+	// whole stereo pairs only
+	n = copy(buf[:len(buf)&^1], o.samples)
+	o.samples = o.samples[:copy(o.samples, o.samples[n:])]
+
+	return n
+}
+
+// sampleRate is SampleRate, or the default if it isn't set
+func (gb *GameBoy) sampleRate() (rate int) {
+	if gb.SampleRate > 0 {
+		return gb.SampleRate
+	}
+
+	return DefaultSampleRate
+}
+
+// outputAudio takes an M-cycle of the mixer's output, producing a sample
+// whenever one is due
+func (gb *GameBoy) outputAudio(left, right int) {
+	o := &gb.apu.out
+	levels := [2]int{left, right}
+
+	if gb.AudioQuality == AudioBandLimited {
+		for ch, level := range levels {
+			if level != o.level[ch] {
+				o.addStep(ch, level-o.level[ch])
+				o.level[ch] = level
+			}
+		}
+	} else {
+		o.sum[0] += left
+		o.sum[1] += right
+		o.count++
+	}
+
+	o.clock += gb.sampleRate() * 4
+	if o.clock < ClockSpeed {
+		return
+	}
+
+	o.clock -= ClockSpeed
+
+	var sample [2]float64
+
+	if gb.AudioQuality == AudioBandLimited {
+		for ch := range sample {
+			o.integral[ch] += o.steps[ch][o.next]
+			o.steps[ch][o.next] = 0
+			sample[ch] = o.integral[ch]
+		}
+
+		o.next = (o.next + 1) & (blipSize - 1)
+	} else if o.count > 0 {
+		for ch := range sample {
+			sample[ch] = float64(o.sum[ch]) / float64(o.count)
+		}
+
+		o.sum, o.count = [2]int{}, 0
+	}
+
+	gb.emitSample(sample)
+}
+
+// addStep spreads a change in level at the current time over the samples
+// that follow it
+func (o *audioOutput) addStep(ch int, delta int) {
+	phase := o.clock * blipPhases / ClockSpeed
+
+	for tap, weight := range blipKernel[phase] {
+		o.steps[ch][(o.next+1+tap)&(blipSize-1)] += float64(delta) * weight
+	}
+}
+
+// emitSample runs a sample through the high-pass filter and queues it for
+// ReadAudio, unless the buffer is full
+func (gb *GameBoy) emitSample(sample [2]float64) {
+	o := &gb.apu.out
+
+	if o.chargeRate != gb.sampleRate() || o.chargeModel != gb.Model || o.charge == 0 {
+		charge, ok := highPassCharges[gb.Model]
+		if !ok {
+			charge = highPassCharges[ModelDMG]
+		}
+
+		o.chargeRate, o.chargeModel = gb.sampleRate(), gb.Model
+		o.charge = math.Pow(charge, float64(ClockSpeed)/float64(o.chargeRate))
+	}
+
+	// the filter keeps charging while the buffer is full, so playback picks
+	// up where it would have been without a jump
+	full := len(o.samples) >= audioBufferFrames*2
+
+	for ch, in := range sample {
+		out := in - o.capacitor[ch]
+		o.capacitor[ch] = in - out*o.charge
+
+		if full {
+			continue
+		}
+
+		out = math.Round(out * mixerScale)
+		out = math.Max(math.Min(out, math.MaxInt16), math.MinInt16)
+
+		o.samples = append(o.samples, int16(out))
+	}
+}
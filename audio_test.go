@@ -0,0 +1,127 @@
+package goboy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rms is the root mean square of one side of interleaved stereo samples
+func rms(samples []int16) (level float64) {
+	var sum float64
+	for i := 0; i < len(samples); i += 2 {
+		sum += float64(samples[i]) * float64(samples[i])
+	}
+
+	return math.Sqrt(sum / float64(len(samples)/2))
+}
+
+// playSquare plays channel 2 at full volume with a 50% duty cycle
+func playSquare(gb *GameBoy, frequency uint16) {
+	gb.WriteMemory(NR22, 0xF0)
+	gb.WriteMemory(NR21, 0x80)
+	gb.WriteMemory(NR23, uint8(frequency))
+	gb.WriteMemory(NR24, 0x80|uint8(frequency>>8))
+}
+
+func TestReadAudio(t *testing.T) {
+	for _, quality := range []AudioQuality{AudioBandLimited, AudioAveraged} {
+		gb := newTestAPU()
+		gb.SampleRate = 48000
+		gb.AudioQuality = quality
+
+		playSquare(gb, 0x700) // 1024 T-cycles per step, 512Hz
+
+		// past the pop of the DAC turning on
+		buf := make([]int16, 10000)
+		ticks(gb, ClockSpeed/4/10)
+		gb.ReadAudio(buf)
+
+		// a 60th of a second
+		ticks(gb, ClockSpeed/4/60)
+
+		n := gb.ReadAudio(buf)
+		assert.InDelta(t, 1600, n, 2)
+		assert.Zero(t, gb.ReadAudio(buf), "drained")
+
+		for i := 0; i < n; i += 2 {
+			assert.Equal(t, buf[i], buf[i+1], "both sides")
+		}
+
+		// a full volume square wave is 15*8 either side of 0, give or take
+		// the filtering
+		assert.InEpsilon(t, 15*8*mixerScale, rms(buf[:n]), 0.1, "quality %d", quality)
+
+		// an odd length buffer only gets whole pairs
+		ticks(gb, 100)
+		assert.Equal(t, 2, gb.ReadAudio(buf[:3]))
+	}
+}
+
+func TestBandLimited(t *testing.T) {
+	buf := make([]int16, 48000)
+
+	// about 26kHz, past what 48kHz can represent
+	levels := map[AudioQuality]float64{}
+	for _, quality := range []AudioQuality{AudioBandLimited, AudioAveraged} {
+		gb := newTestAPU()
+		gb.SampleRate = 48000
+		gb.AudioQuality = quality
+
+		playSquare(gb, 2043)
+
+		// let the high-pass filter settle first
+		ticks(gb, ClockSpeed/4/2)
+		gb.ReadAudio(buf)
+
+		ticks(gb, ClockSpeed/4/10)
+		levels[quality] = rms(buf[:gb.ReadAudio(buf)])
+	}
+
+	// averaging lets a loud alias through, band-limiting leaves next to nothing
+	assert.Greater(t, levels[AudioAveraged], 1000.0)
+	assert.Less(t, levels[AudioBandLimited], 50.0)
+}
+
+func TestHighPass(t *testing.T) {
+	buf := make([]int16, 48000)
+
+	// how loud a DAC that is on but silent still is after 10ms
+	levels := map[Model]float64{}
+	for _, model := range []Model{ModelDMG, ModelMGB} {
+		gb := newTestAPU()
+		gb.Model = model
+
+		gb.WriteMemory(NR22, 0x08)
+		ticks(gb, ClockSpeed/4/10)
+
+		n := gb.ReadAudio(buf)
+		assert.Less(t, buf[blipTaps], int16(-15*mixerScale), "starts near the DAC's level")
+		assert.Zero(t, buf[n-2], "and fades to silence")
+
+		levels[model] = math.Abs(float64(buf[DefaultSampleRate/100*2]))
+	}
+
+	// the DMG's capacitor charges much slower
+	assert.Greater(t, levels[ModelDMG], 15*mixerScale/10.0)
+	assert.Less(t, levels[ModelMGB], 1.0)
+}
+
+func TestHighPassWhileFull(t *testing.T) {
+	gb := newTestAPU()
+	buf := make([]int16, audioBufferFrames*2)
+
+	// fill the buffer with silence, then turn a DAC on while nobody reads
+	ticks(gb, ClockSpeed/4)
+	gb.WriteMemory(NR22, 0x08)
+	ticks(gb, ClockSpeed/4/2)
+	gb.ReadAudio(buf)
+
+	// the capacitor charged all the same, there is no jump once reading resumes
+	ticks(gb, ClockSpeed/4/100)
+	n := gb.ReadAudio(buf)
+	assert.NotZero(t, n)
+	assert.Zero(t, buf[0])
+	assert.Zero(t, buf[n-1])
+}
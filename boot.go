@@ -0,0 +1,175 @@
+package goboy
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// BootROMSize is the size of the DMG family boot ROMs
+const BootROMSize = 0x0100
+
+// Model identifies which Game Boy hardware revision is being emulated. It only
+// matters when the boot sequence is skipped, because the boot ROMs leave
+// slightly different register values behind.
+type Model uint8
+
+const (
+	ModelDMG  Model = iota // Game Boy, the default
+	ModelDMG0              // early Japanese Game Boy
+	ModelMGB               // Game Boy Pocket
+	ModelSGB               // Super Game Boy
+	ModelSGB2              // Super Game Boy 2
+)
+
+// postBootState is what the boot ROM leaves behind when it hands control to the
+// cartridge at 0x0100, see https://gbdev.io/pandocs/Power_Up_Sequence.html
+type postBootState struct {
+	a, f, b, c, d, e, h, l uint8
+	div                    uint8
+	nr52                   uint8
+}
+
+var postBootStates = map[Model]postBootState{
+	ModelDMG0: {0x01, 0x00, 0xFF, 0x13, 0x00, 0xC1, 0x84, 0x03, 0x18, 0xF1},
+	ModelDMG:  {0x01, 0x80, 0x00, 0x13, 0x00, 0xD8, 0x01, 0x4D, 0xAB, 0xF1},
+	ModelMGB:  {0xFF, 0x80, 0x00, 0x13, 0x00, 0xD8, 0x01, 0x4D, 0xAB, 0xF1},
+	ModelSGB:  {0x01, 0x00, 0x00, 0x14, 0x00, 0x00, 0xC0, 0x60, 0x00, 0xF0},
+	ModelSGB2: {0xFF, 0x00, 0x00, 0x14, 0x00, 0x00, 0xC0, 0x60, 0x00, 0xF0},
+}
+
+// postBootIO are the I/O registers the boot ROM leaves in a known state, the
+// same on every DMG family model apart from DIV and NR52
+var postBootIO = []struct {
+	address uint16
+	value   uint8
+}{
+	{JOYP, 0xCF},
+	{SB, 0x00},
+	{SC, 0x7E},
+	{TIMA, 0x00},
+	{TMA, 0x00},
+	{TAC, 0xF8},
+	{IF, 0xE1},
+	{NR10, 0x80},
+	{NR11, 0xBF},
+	{NR12, 0xF3},
+	{NR13, 0xFF},
+	{NR14, 0xBF},
+	{NR21, 0x3F},
+	{NR22, 0x00},
+	{NR23, 0xFF},
+	{NR24, 0xBF},
+	{NR30, 0x7F},
+	{NR31, 0xFF},
+	{NR32, 0x9F},
+	{NR33, 0xFF},
+	{NR34, 0xBF},
+	{NR41, 0xFF},
+	{NR42, 0x00},
+	{NR43, 0x00},
+	{NR44, 0xBF},
+	{NR50, 0x77},
+	{NR51, 0xF3},
+	{LCDC, 0x91},
+	{STAT, 0x85},
+	{SCY, 0x00},
+	{SCX, 0x00},
+	{LY, 0x00},
+	{LYC, 0x00},
+	{BGP, 0xFC},
+	{OBP0, 0xFF},
+	{OBP1, 0xFF},
+	{WY, 0x00},
+	{WX, 0x00},
+}
+
+// LoadBootROM sets the boot ROM that overlays 0x0000-0x00FF after the next
+// Reset or LoadROM, until software writes to 0xFF50
+func (gb *GameBoy) LoadBootROM(d []byte) (err error) {
+	if len(d) != BootROMSize {
+		return errors.New(fmt.Sprintf("boot ROM must be %d bytes, got %d", BootROMSize, len(d)))
+	}
+
+	gb.bootROM = d
+
+	return nil
+}
+
+// Reset power cycles the GameBoy, keeping the loaded ROMs. Execution starts in
+// the boot ROM when one is loaded, otherwise (or if SkipBoot is set) at 0x0100
+// in the state the boot ROM would have left behind.
+func (gb *GameBoy) Reset() {
+	gb.a, gb.f, gb.b, gb.c, gb.d, gb.e, gb.h, gb.l = 0, 0, 0, 0, 0, 0, 0, 0
+	gb.pc, gb.sp = 0, 0
+	gb.tickCount = 0
+	gb.ime, gb.eiPending = false, false
+	gb.halted, gb.haltBug, gb.stopped = false, false, false
+
+	gb.vram = [len(gb.vram)]uint8{}
+	gb.wram = [len(gb.wram)]uint8{}
+	gb.oam = [len(gb.oam)]uint8{}
+	gb.io = [len(gb.io)]uint8{}
+	gb.hram = [len(gb.hram)]uint8{}
+	gb.ie = 0
+	gb.joypad = joypad{}
+	gb.joypad.lines = gb.joypadLines() // with the selection reset
+	gb.cancelSerial()
+	gb.serial = serial{}
+	gb.timer = timer{}
+	gb.ppu = ppu{}
+	gb.dma = dma{}
+	gb.apu = apu{}
+
+	if gb.bootROM != nil && !gb.SkipBoot {
+		gb.bootMapped = true
+		return
+	}
+
+	gb.bootMapped = false
+	gb.skipBoot()
+}
+
+// skipBoot puts the GameBoy in the state the boot ROM leaves it in for the
+// configured model
+func (gb *GameBoy) skipBoot() {
+	state, ok := postBootStates[gb.Model]
+	if !ok {
+		state = postBootStates[ModelDMG]
+	}
+
+	gb.a, gb.f = state.a, state.f
+	gb.b, gb.c = state.b, state.c
+	gb.d, gb.e = state.d, state.e
+	gb.h, gb.l = state.h, state.l
+
+	if (gb.Model == ModelDMG || gb.Model == ModelMGB) && gb.ReadRom8(0x014D) != 0 {
+		// the boot ROM's header checksum loop leaves H and C set unless the
+		// checksum happened to be zero
+		gb.f |= MaskHalfCarryFlag | MaskCarryFlag
+	}
+
+	gb.sp = 0xFFFE
+	gb.pc = 0x0100
+
+	// NR52 first, the sound registers ignore writes while the APU is off
+	gb.WriteMemory(NR52, state.nr52)
+
+	for _, reg := range postBootIO {
+		gb.WriteMemory(reg.address, reg.value)
+	}
+
+	// NR14's write triggers channel 1, which only the DMG boot ROMs leave
+	// playing after their chime
+	gb.apu.ch1.on = state.nr52&0x01 != 0
+
+	// writing DIV would reset it, and writing DMA would start a transfer
+	gb.timer.counter = uint16(state.div) << 8
+	gb.dma.register = 0xFF
+
+	// the boot ROM hands over during the last line of VBlank, after LY already
+	// reads as 0, which is where STAT's 0x85 comes from
+	gb.ppu.line, gb.ppu.dot, gb.ppu.mode = linesPerFrame-1, 4, modeVBlank
+	gb.ppu.coincidence = true
+	gb.WriteMemory(IE, 0x00)
+}
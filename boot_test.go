@@ -0,0 +1,73 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootROMOverlay(t *testing.T) {
+	boot := make([]byte, BootROMSize)
+	copy(boot, []byte{
+		0x3E, 0x01, // LD A, 1
+		0xE0, 0x50, // LD (0xFF00+0x50), A
+	})
+
+	rom := newTestROM(0x00, 0x00, 0x00)
+	rom[0x0000] = 0xAA
+	fixChecksums(rom)
+
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadBootROM(boot))
+	assert.NoError(t, gb.LoadROM(rom))
+
+	assert.Equal(t, uint16(0x0000), gb.pc)
+	assert.Equal(t, uint8(0x3E), gb.ReadMemory(0x0000))
+
+	gb.RunInstruction()
+	gb.RunInstruction()
+
+	// the cartridge shows through once 0xFF50 has been written
+	assert.Equal(t, uint8(0xAA), gb.ReadMemory(0x0000))
+
+	// and the boot ROM can't be mapped back in
+	gb.WriteMemory(BOOT, 0x00)
+	assert.Equal(t, uint8(0xAA), gb.ReadMemory(0x0000))
+}
+
+func TestBootROMSize(t *testing.T) {
+	gb := &GameBoy{}
+	assert.Error(t, gb.LoadBootROM(make([]byte, 0x0900)))
+}
+
+func TestSkipBoot(t *testing.T) {
+	rom := newTestROM(0x00, 0x00, 0x00)
+	assert.NotZero(t, rom[0x014D])
+
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadBootROM(make([]byte, BootROMSize)))
+	gb.SkipBoot = true
+	assert.NoError(t, gb.LoadROM(rom))
+
+	assert.Equal(t, uint16(0x0100), gb.pc)
+	assert.Equal(t, uint16(0xFFFE), gb.sp)
+	assert.Equal(t, uint16(0x01B0), gb.readAF())
+	assert.Equal(t, uint16(0x0013), gb.readBC())
+	assert.Equal(t, uint16(0x00D8), gb.readDE())
+	assert.Equal(t, uint16(0x014D), gb.readHL())
+	assert.Equal(t, uint8(0x91), gb.ReadMemory(LCDC))
+	assert.Equal(t, uint8(0xFC), gb.ReadMemory(BGP))
+	assert.Equal(t, uint8(0x85), gb.ReadMemory(STAT))
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(LY))
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(DMA))
+	assert.Equal(t, uint8(0xAB), gb.ReadMemory(DIV))
+	assert.Equal(t, uint8(0xF1), gb.ReadMemory(NR52))
+
+	gb.Model = ModelSGB
+	gb.Reset()
+
+	assert.Equal(t, uint16(0x0100), gb.readAF())
+	assert.Equal(t, uint16(0x0014), gb.readBC())
+	assert.Equal(t, uint16(0xC060), gb.readHL())
+	assert.Equal(t, uint8(0xF0), gb.ReadMemory(NR52))
+}
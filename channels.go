@@ -0,0 +1,312 @@
+package goboy
+
+// square channel duty cycles, played from the most significant bit down:
+// 12.5%, 25%, 50% and 75%
+var dutyWaves = [4]uint8{0b0000_0001, 0b1000_0001, 0b1000_0111, 0b0111_1110}
+
+// waveShifts turn a wave sample into the NR32 output level: mute, 100%, 50% and 25%
+var waveShifts = [4]uint8{4, 0, 1, 2}
+
+// noiseDivisors are the noise channel's base periods in T-cycles, by NR43's divisor code
+var noiseDivisors = [8]int{8, 16, 32, 48, 64, 80, 96, 112}
+
+// lengthCounter turns a channel off after a number of 256Hz frame sequencer
+// steps, when enabled
+type lengthCounter struct {
+	counter int  // steps left
+	enabled bool // NRx4 bit 6
+}
+
+// load sets the counter from an NRx1 write, length counters count up to size
+func (l *lengthCounter) load(size int, value int) {
+	l.counter = size - value
+}
+
+// clock steps the counter, returning true when it runs out
+func (l *lengthCounter) clock() (expired bool) {
+	if !l.enabled || l.counter == 0 {
+		return false
+	}
+
+	l.counter--
+
+	return l.counter == 0
+}
+
+// envelope is the volume envelope of the square and noise channels
+type envelope struct {
+	initial uint8 // volume on trigger
+	up      bool  // louder instead of quieter
+	period  uint8 // 64Hz frame sequencer steps between changes, 0 holds the volume
+	timer   uint8 // steps left until the next change
+	volume  uint8 // current volume, 0-15
+}
+
+// write sets the envelope from an NRx2 write, it takes effect on the next trigger
+func (e *envelope) write(value uint8) {
+	e.initial = value >> 4
+	e.up = value&0x08 != 0
+	e.period = value & 0x07
+}
+
+func (e *envelope) trigger() {
+	e.volume = e.initial
+	e.timer = e.period
+}
+
+func (e *envelope) clock() {
+	if e.period == 0 {
+		return
+	}
+
+	// the timer is 0 when the period was set after the trigger
+	if e.timer > 0 {
+		e.timer--
+	}
+
+	if e.timer > 0 {
+		return
+	}
+
+	e.timer = e.period
+
+	if e.up && e.volume < 15 {
+		e.volume++
+	} else if !e.up && e.volume > 0 {
+		e.volume--
+	}
+}
+
+// square is one of the two square wave channels, only channel 1 has a sweep
+// see https://gbdev.io/pandocs/Audio_Registers.html#sound-channel-1--pulse-with-period-sweep
+type square struct {
+	on     bool // playing, as reported by NR52
+	dac    bool // the DAC is on, the upper 5 bits of NRx2 aren't all 0
+	length lengthCounter
+	env    envelope
+
+	duty      uint8  // NRx1 bits 6-7
+	position  uint8  // step through the duty wave, 0-7
+	frequency uint16 // 11 bits from NRx3 and NRx4
+	timer     int    // T-cycles until the next step
+
+	sweepPeriod  uint8  // NR10 bits 4-6
+	sweepNegate  bool   // NR10 bit 3
+	sweepShift   uint8  // NR10 bits 0-2
+	sweepTimer   uint8  // 128Hz frame sequencer steps until the next sweep
+	sweepEnabled bool   // set on trigger when the sweep does anything
+	shadow       uint16 // frequency the sweep works from
+	negated      bool   // a subtraction was calculated since the last trigger
+}
+
+func (s *square) period() (cycles int) {
+	return (2048 - int(s.frequency)) * 4
+}
+
+// step advances the duty wave by some T-cycles
+func (s *square) step(cycles int) {
+	s.timer -= cycles
+
+	for s.timer <= 0 {
+		s.timer += s.period()
+		s.position = (s.position + 1) & 0x07
+	}
+}
+
+// output is the channel's digital output, 0-15
+func (s *square) output() (level uint8) {
+	if !s.on || (dutyWaves[s.duty]>>(7-s.position))&1 == 0 {
+		return 0
+	}
+
+	return s.env.volume
+}
+
+func (s *square) trigger() {
+	s.on = s.dac
+	s.timer = s.period()
+	s.env.trigger()
+
+	s.shadow = s.frequency
+	s.sweepTimer = s.sweepPeriod
+	if s.sweepTimer == 0 {
+		s.sweepTimer = 8
+	}
+
+	s.sweepEnabled = s.sweepPeriod != 0 || s.sweepShift != 0
+	s.negated = false
+
+	if s.sweepShift != 0 {
+		// the overflow check runs straight away
+		if _, overflow := s.sweepFrequency(); overflow {
+			s.on = false
+		}
+	}
+}
+
+// writeSweep handles a write to NR10
+func (s *square) writeSweep(value uint8) {
+	s.sweepPeriod = (value >> 4) & 0x07
+	s.sweepNegate = value&0x08 != 0
+	s.sweepShift = value & 0x07
+
+	// going back to addition after a subtraction was used turns the channel off
+	if !s.sweepNegate && s.negated {
+		s.on = false
+	}
+}
+
+// sweepFrequency calculates the next frequency from the shadow frequency
+func (s *square) sweepFrequency() (frequency uint16, overflow bool) {
+	delta := s.shadow >> s.sweepShift
+
+	if s.sweepNegate {
+		s.negated = true
+		return s.shadow - delta, false
+	}
+
+	frequency = s.shadow + delta
+
+	return frequency, frequency > 2047
+}
+
+func (s *square) clockSweep() {
+	if s.sweepTimer > 0 {
+		s.sweepTimer--
+	}
+
+	if s.sweepTimer > 0 {
+		return
+	}
+
+	s.sweepTimer = s.sweepPeriod
+	if s.sweepTimer == 0 {
+		s.sweepTimer = 8
+	}
+
+	if !s.sweepEnabled || s.sweepPeriod == 0 {
+		return
+	}
+
+	frequency, overflow := s.sweepFrequency()
+	if overflow {
+		s.on = false
+		return
+	}
+
+	if s.sweepShift == 0 {
+		return
+	}
+
+	s.shadow, s.frequency = frequency, frequency
+
+	// and the new frequency is checked again, without being used
+	if _, overflow = s.sweepFrequency(); overflow {
+		s.on = false
+	}
+}
+
+// wave is the channel that plays 32 4-bit samples from wave RAM
+// see https://gbdev.io/pandocs/Audio_Registers.html#sound-channel-3--wave-output
+type wave struct {
+	on     bool // playing, as reported by NR52
+	dac    bool // NR30 bit 7
+	length lengthCounter
+
+	volume    uint8  // NR32 output level, see waveShifts
+	frequency uint16 // 11 bits from NR33 and NR34
+	timer     int    // T-cycles until the next sample
+	position  uint8  // sample being played, 0-31
+	sample    uint8  // the sample last read from wave RAM
+}
+
+func (w *wave) period() (cycles int) {
+	return (2048 - int(w.frequency)) * 2
+}
+
+// step advances through wave RAM by some T-cycles
+func (w *wave) step(cycles int, ram *[16]uint8) {
+	w.timer -= cycles
+
+	for w.timer <= 0 {
+		w.timer += w.period()
+		w.position = (w.position + 1) & 0x1F
+
+		// the high nibble plays first
+		w.sample = ram[w.position/2]
+		if w.position%2 == 0 {
+			w.sample >>= 4
+		}
+
+		w.sample &= 0x0F
+	}
+}
+
+func (w *wave) output() (level uint8) {
+	if !w.on {
+		return 0
+	}
+
+	return w.sample >> waveShifts[w.volume]
+}
+
+func (w *wave) trigger() {
+	w.on = w.dac
+	w.timer = w.period()
+	w.position = 0
+}
+
+// noise is the channel driven by a linear feedback shift register
+// see https://gbdev.io/pandocs/Audio_Registers.html#sound-channel-4--noise
+type noise struct {
+	on     bool // playing, as reported by NR52
+	dac    bool // the DAC is on, the upper 5 bits of NR42 aren't all 0
+	length lengthCounter
+	env    envelope
+
+	shift   uint8  // NR43 bits 4-7
+	narrow  bool   // NR43 bit 3, the LFSR is 7 bits instead of 15
+	divisor uint8  // NR43 bits 0-2, see noiseDivisors
+	lfsr    uint16 // the shift register, bit 0 is the output inverted
+	timer   int    // T-cycles until the next shift
+}
+
+func (n *noise) period() (cycles int) {
+	return noiseDivisors[n.divisor] << n.shift
+}
+
+// step shifts the LFSR as often as some T-cycles allow
+func (n *noise) step(cycles int) {
+	if n.shift >= 14 {
+		// the shift register isn't clocked at all
+		return
+	}
+
+	n.timer -= cycles
+
+	for n.timer <= 0 {
+		n.timer += n.period()
+
+		bit := (n.lfsr ^ n.lfsr>>1) & 1
+		n.lfsr = n.lfsr>>1 | bit<<14
+
+		if n.narrow {
+			n.lfsr = n.lfsr&^(1<<6) | bit<<6
+		}
+	}
+}
+
+func (n *noise) output() (level uint8) {
+	if !n.on || n.lfsr&1 != 0 {
+		return 0
+	}
+
+	return n.env.volume
+}
+
+func (n *noise) trigger() {
+	n.on = n.dac
+	n.timer = n.period()
+	n.lfsr = 0x7FFF
+	n.env.trigger()
+}
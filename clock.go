@@ -0,0 +1,32 @@
+package goboy
+
+const (
+	ClockSpeed     = 4194304 // T-cycles per second
+	CyclesPerFrame = 70224   // T-cycles in a frame, 154 lines of 456 dots each
+)
+
+// tick advances the GameBoy by one M-cycle (4 T-cycles). Everything besides the
+// CPU steps along with it, the CPU calls it for every memory access and every
+// internal delay so the other components see accesses on the right cycle.
+func (gb *GameBoy) tick() {
+	gb.tickCount += 4
+
+	gb.timerTick()
+	gb.dmaTick()
+	gb.ppuTick()
+	gb.apuTick()
+}
+
+// cpuRead is a memory read by the CPU, taking an M-cycle
+func (gb *GameBoy) cpuRead(address uint16) (value byte) {
+	gb.tick()
+
+	return gb.ReadMemory(address)
+}
+
+// cpuWrite is a memory write by the CPU, taking an M-cycle
+func (gb *GameBoy) cpuWrite(address uint16, value byte) {
+	gb.tick()
+
+	gb.WriteMemory(address, value)
+}
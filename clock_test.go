@@ -0,0 +1,72 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wacul/ptr"
+)
+
+func TestInstructionTiming(t *testing.T) {
+	run := func(t *testing.T, name string, ops OpBytes, program ...uint8) {
+		for _, f := range []uint8{0x00, 0xF0} {
+			gb := newTestCPU(program...)
+			gb.f = f
+			gb.setHL(0xC100)
+
+			gb.RunInstruction()
+
+			expected := ops.Cycles
+			if gb.branched {
+				expected = ops.BranchCycles
+			}
+
+			assert.Equal(t, uint64(expected)*4, gb.tickCount, "%s with flags %.2X", name, f)
+		}
+	}
+
+	for k, v := range unprefixed {
+		if v.Code == HALT || v.Code == STOP {
+			continue
+		}
+
+		run(t, formatInst(k, nil), v, k, 0x00, 0xC1)
+	}
+
+	for k, v := range cb {
+		run(t, formatInst(k, ptr.String("cb")), v, 0xCB, k)
+	}
+}
+
+func TestSuspendedTiming(t *testing.T) {
+	gb := newTestCPU(0x76) // HALT
+
+	gb.RunInstruction()
+	assert.Equal(t, uint64(4), gb.tickCount)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint64(8), gb.tickCount)
+}
+
+func TestRunFrame(t *testing.T) {
+	gb := newTestCPU(0x18, 0xFE) // JR -2
+
+	// with the LCD off there's no VBlank to stop at
+	gb.RunFrame()
+	assert.GreaterOrEqual(t, gb.tickCount, uint64(CyclesPerFrame))
+	assert.Less(t, gb.tickCount, uint64(CyclesPerFrame+12), "ends with the instruction the frame ended in")
+
+	gb.WriteMemory(LCDC, lcdcEnable)
+	start := gb.tickCount
+	vblank := uint64(ScreenHeight * dotsPerLine)
+
+	gb.RunFrame()
+	assert.Equal(t, uint8(ScreenHeight), gb.ly())
+	assert.Equal(t, modeVBlank, gb.ppu.mode)
+	assert.GreaterOrEqual(t, gb.tickCount-start, vblank)
+	assert.Less(t, gb.tickCount-start, vblank+12)
+
+	gb.RunFrame()
+	assert.GreaterOrEqual(t, gb.tickCount-start, vblank+CyclesPerFrame)
+	assert.Less(t, gb.tickCount-start, vblank+CyclesPerFrame+12)
+}
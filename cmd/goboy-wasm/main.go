@@ -4,10 +4,14 @@
 package main
 
 import (
+	"bytes"
 	"container/ring"
+	"encoding/binary"
 	"fmt"
 	"image"
 	"image/draw"
+	"image/png"
+	"math"
 	"strings"
 	"syscall/js"
 
@@ -19,6 +23,11 @@ import (
 const (
 	width  = 160
 	height = 144
+
+	audioLatency = 0.05 // seconds of audio queued up when playback (re)starts
+	audioMaxLead = 0.25 // seconds of audio queued up before more is dropped
+
+	printURLLifetime = 60000 // milliseconds a print's download URL is kept around
 )
 
 var ( // constant-like variables
@@ -28,15 +37,20 @@ var ( // constant-like variables
 
 	// JS Types
 	Uint8ClampedArray = window.Get("Uint8ClampedArray")
+	Uint8Array        = window.Get("Uint8Array")
+	Float32Array      = window.Get("Float32Array")
 	ImageData         = window.Get("ImageData")
 	AudioContext      = window.Get("AudioContext")
+	Blob              = window.Get("Blob")
+	URL               = window.Get("URL")
 
 	// JS Functions
 	requestAnimationFrame = window.Get("requestAnimationFrame")
 
 	// JS Global Objects
-	document = window.Get("document")
-	console  = window.Get("console")
+	document  = window.Get("document")
+	console   = window.Get("console")
+	navigator = window.Get("navigator")
 )
 
 var (
@@ -45,9 +59,12 @@ var (
 	jsOnFrame      js.Func
 	audioCtx       js.Value // AudioContext
 	audioCtxDest   js.Value // AudioDestinationNode
-	oscillator     js.Value // OscillatorNode
 	gain           js.Value // GainNode
-	curGain        float32  = 0.05
+	curGain        float32  = 0.5
+	sampleRate     float64
+	audioSamples   []int16  // interleaved stereo, from ReadAudio
+	audioBytes     []byte   // one channel of float32 samples for copying to JS
+	nextAudioTime  float64  // AudioContext time the next queued buffer starts at
 	pixelData      js.Value // Uint8ClampedArray
 	fps            js.Value // HTMLSpanElement
 	progress       float64
@@ -59,6 +76,21 @@ var (
 	fpsHistory     *ring.Ring      // history of the last [fpsHistorySize] frame times in seconds
 	fpsHistorySize int        = 10 // size of the history ring
 	frameCount     uint64
+	romLoaded      bool
+	printer        = &goboy.Printer{OnPrint: savePrint}
+	printCount     int
+
+	// keyboard layout, by KeyboardEvent.code
+	keyButtons = map[string]goboy.Button{
+		"ArrowRight": goboy.ButtonRight,
+		"ArrowLeft":  goboy.ButtonLeft,
+		"ArrowUp":    goboy.ButtonUp,
+		"ArrowDown":  goboy.ButtonDown,
+		"KeyX":       goboy.ButtonA,
+		"KeyZ":       goboy.ButtonB,
+		"Backspace":  goboy.ButtonSelect,
+		"Enter":      goboy.ButtonStart,
+	}
 
 	gb *goboy.GameBoy = &goboy.GameBoy{}
 )
@@ -74,6 +106,11 @@ func init() {
 	window.Set("stopWASM", js.FuncOf(stopWASM))
 	window.Set("loadROM", js.FuncOf(loadROM))
 	window.Set("_toggleFPS", js.FuncOf(toggleFPS))
+	window.Set("_togglePrinter", js.FuncOf(togglePrinter))
+	document.Call("addEventListener", "keydown", js.FuncOf(onKey(gb.Press)))
+	document.Call("addEventListener", "keyup", js.FuncOf(onKey(gb.Release)))
+
+	gb.Rumble = rumble
 }
 
 func main() {
@@ -92,13 +129,14 @@ func main() {
 	// setup audio stuff
 	audioCtx = AudioContext.New()
 	audioCtxDest = audioCtx.Get("destination")
-	oscillator = audioCtx.Call("createOscillator")
-	oscillator.Set("type", "square")
 	gain = audioCtx.Call("createGain")
-	oscillator.Call("connect", gain)
 	gain.Call("connect", audioCtxDest)
 	gain.Get("gain").Set("value", curGain)
-	// oscillator.Call("start")
+
+	sampleRate = audioCtx.Get("sampleRate").Float()
+	gb.SampleRate = int(sampleRate)
+	audioSamples = make([]int16, int(sampleRate)/10*2)
+	audioBytes = make([]byte, len(audioSamples)/2*4)
 
 	// create image
 	img = image.NewRGBA(image.Rect(0, 0, width, height))
@@ -114,8 +152,6 @@ func main() {
 	// wait for call to stopWASM
 	<-killSwitch
 
-	// oscillator.Call("stop")
-
 	// fill the image with white and clear the canvas
 	draw.Draw(img, image.Rect(0, 0, width, height), image.NewUniform(colornames.White), image.Point{}, draw.Src)
 	drawImage(ctx, img)
@@ -141,18 +177,22 @@ func onFrame(this js.Value, args []js.Value) interface{} {
 		}
 	}
 
-	// inset colored rectangle
-	draw.Draw(img, image.Rect(10, 10, width-10, height-10), image.NewUniform(Keypoints.GetInterpolatedColorFor(progress)), image.Point{}, draw.Src)
-	drawImage(ctx, img)
-
-	// increment progress through the gradient
-	progress += dt / 3
-	if progress > 1 {
-		progress -= 1
+	if romLoaded {
+		gb.RunFrame()
+		drawImage(ctx, gb.Frame())
+		playAudio()
+	} else {
+		// inset colored rectangle until there's something to emulate
+		draw.Draw(img, image.Rect(10, 10, width-10, height-10), image.NewUniform(Keypoints.GetInterpolatedColorFor(progress)), image.Point{}, draw.Src)
+		drawImage(ctx, img)
+
+		// increment progress through the gradient
+		progress += dt / 3
+		if progress > 1 {
+			progress -= 1
+		}
 	}
 
-	// playAudio(ts, dt)
-
 	if !closing {
 		requestAnimationFrame.Invoke(jsOnFrame)
 	} else {
@@ -173,16 +213,45 @@ func drawImage(ctx js.Value, img *image.RGBA) {
 	ctx.Call("putImageData", imgData, 0, 0)
 }
 
-// func playAudio(ts float64, dt float64) {
-// 	if int(ts) != int(ts-dt) {
-// 		if curGain != 0 {
-// 			curGain = 0
-// 		} else {
-// 			curGain = 0.05
-// 		}
-// 		gain.Get("gain").Set("value", curGain)
-// 	}
-// }
+// playAudio queues up the audio emulated since the last call
+func playAudio() {
+	n := gb.ReadAudio(audioSamples)
+	if n == 0 {
+		return
+	}
+
+	now := audioCtx.Get("currentTime").Float()
+	if nextAudioTime < now {
+		// ran dry, start again with a little room
+		nextAudioTime = now + audioLatency
+	}
+
+	if nextAudioTime > now+audioMaxLead {
+		// the emulator is running fast, drop audio to catch up
+		return
+	}
+
+	frames := n / 2
+	buffer := audioCtx.Call("createBuffer", 2, frames, sampleRate)
+
+	for ch := 0; ch < 2; ch++ {
+		for i := 0; i < frames; i++ {
+			sample := float32(audioSamples[i*2+ch]) / math.MaxInt16
+			binary.LittleEndian.PutUint32(audioBytes[i*4:], math.Float32bits(sample))
+		}
+
+		bytes := Uint8Array.New(frames * 4)
+		js.CopyBytesToJS(bytes, audioBytes[:frames*4])
+		buffer.Call("copyToChannel", Float32Array.New(bytes.Get("buffer")), ch)
+	}
+
+	source := audioCtx.Call("createBufferSource")
+	source.Set("buffer", buffer)
+	source.Call("connect", gain)
+	source.Call("start", nextAudioTime)
+
+	nextAudioTime += float64(frames) / sampleRate
+}
 
 func loadROM(this js.Value, args []js.Value) interface{} {
 	fmt.Printf("WASM - loading ROM (%d)\n", len(args))
@@ -204,14 +273,53 @@ func loadROM(this js.Value, args []js.Value) interface{} {
 
 	js.CopyBytesToGo(data, array)
 
-	gb.LoadROM(data)
+	err := gb.LoadROM(data)
+	if err != nil {
+		fmt.Printf("unable to load ROM: %s\n", err)
+		return JSNULL
+	}
 
-	// TODO: run multiple frames
-	gb.RunFrame()
+	// browsers only allow audio to start after user input, like picking a ROM
+	audioCtx.Call("resume")
+
+	// onFrame takes it from here
+	romLoaded = true
 
 	return JSNULL
 }
 
+// onKey makes a keyboard event listener that passes mapped keys to fn
+func onKey(fn func(goboy.Button)) func(js.Value, []js.Value) interface{} {
+	return func(this js.Value, args []js.Value) interface{} {
+		event := args[0]
+
+		button, ok := keyButtons[event.Get("code").String()]
+		if !ok {
+			return JSNULL
+		}
+
+		// keep the arrows from scrolling the page
+		event.Call("preventDefault")
+		fn(button)
+
+		return JSNULL
+	}
+}
+
+// rumble drives the vibration API, where available, from rumble cartridges
+func rumble(on bool) {
+	if !navigator.Get("vibrate").Truthy() {
+		return
+	}
+
+	if on {
+		// games pulse the motor, each pulse turns it off again
+		navigator.Call("vibrate", 1000)
+	} else {
+		navigator.Call("vibrate", 0)
+	}
+}
+
 func stopWASM(this js.Value, args []js.Value) interface{} {
 	closing = true
 	return JSNULL
@@ -235,3 +343,54 @@ func toggleFPS(_ js.Value, _ []js.Value) interface{} {
 
 	return JSNULL
 }
+
+// togglePrinter plugs the printer into the link port or unplugs it, returning
+// whether it is plugged in
+func togglePrinter(_ js.Value, _ []js.Value) interface{} {
+	if gb.Link == nil {
+		gb.Link = printer
+		return true
+	}
+
+	// anything left on the paper is torn off
+	printer.Cut()
+	gb.Link = nil
+
+	return false
+}
+
+// savePrint downloads a print from the printer as a PNG
+func savePrint(print image.Image) {
+	var buf bytes.Buffer
+
+	err := png.Encode(&buf, print)
+	if err != nil {
+		fmt.Printf("unable to encode print: %s\n", err)
+		return
+	}
+
+	data := Uint8Array.New(buf.Len())
+	js.CopyBytesToJS(data, buf.Bytes())
+
+	blob := Blob.New([]interface{}{data}, map[string]interface{}{"type": "image/png"})
+	url := URL.Call("createObjectURL", blob)
+
+	printCount++
+
+	link := document.Call("createElement", "a")
+	link.Set("href", url)
+	link.Set("download", fmt.Sprintf("print-%d.png", printCount))
+	link.Call("click")
+
+	// some browsers start the download after click returns, give them time
+	// before the URL goes away
+	var revoke js.Func
+	revoke = js.FuncOf(func(_ js.Value, _ []js.Value) interface{} {
+		URL.Call("revokeObjectURL", url)
+		revoke.Release()
+
+		return JSNULL
+	})
+
+	window.Call("setTimeout", revoke, printURLLifetime)
+}
@@ -0,0 +1,94 @@
+package goboy
+
+const dmaLength = 0xA0 // bytes copied by an OAM DMA transfer, the size of OAM
+
+// dma is the state of an OAM DMA transfer, which copies a page of memory into
+// OAM at a byte per M-cycle. While it runs the CPU can't see OAM, and reading
+// from the same bus the transfer is reading from returns the byte being
+// copied instead. That's why games run their DMA routine from HRAM.
+// see https://gbdev.io/pandocs/OAM_DMA_Transfer.html
+type dma struct {
+	register uint8  // last value written to DMA
+	source   uint16 // start of the page being copied
+	index    int    // next byte to copy
+	active   bool   // a transfer is running
+	starting bool   // a transfer starts after the next M-cycle, replacing any running one
+
+	transferring bool  // a byte was copied this M-cycle, the buses are taken
+	value        uint8 // the byte copied this M-cycle
+}
+
+// startDMA is a write to the DMA register
+func (gb *GameBoy) startDMA(value uint8) {
+	gb.dma.register = value
+	gb.dma.starting = true
+}
+
+// dmaTick advances DMA by an M-cycle
+func (gb *GameBoy) dmaTick() {
+	d := &gb.dma
+
+	d.transferring = false
+
+	if d.active {
+		d.value = gb.readDMASource(d.source + uint16(d.index))
+		gb.oam[d.index] = d.value
+
+		d.transferring = true
+		d.index++
+		d.active = d.index < dmaLength
+	}
+
+	if d.starting {
+		// there's a cycle of setup before the first byte, a transfer that is
+		// already running carries on through it
+		d.starting = false
+		d.active = true
+		d.index = 0
+		d.source = uint16(d.register) << 8
+
+		if d.source >= 0xE000 {
+			// above WRAM it reads from the echo, which echoes all of WRAM here
+			d.source -= 0x2000
+		}
+	}
+}
+
+// readDMASource reads memory the way DMA sees it, without the CPU's restrictions
+func (gb *GameBoy) readDMASource(address uint16) (value byte) {
+	switch {
+	case address < 0x8000:
+		return gb.ReadRom8(address)
+	case address < 0xA000:
+		return gb.vram[address-0x8000]
+	case address < 0xC000:
+		return gb.readCartRAM(address)
+	default:
+		return gb.wram[address-0xC000]
+	}
+}
+
+// dmaConflict is whether a CPU access to address clashes with a running DMA
+// transfer. OAM is unavailable, and so is whichever bus DMA is reading from,
+// the external bus (cartridge and WRAM) or the video bus (VRAM).
+func (gb *GameBoy) dmaConflict(address uint16) (conflict bool) {
+	if !gb.dma.transferring {
+		return false
+	}
+
+	if address >= 0xFE00 && address < 0xFF00 {
+		return true
+	}
+
+	if address >= 0xFE00 {
+		// I/O and HRAM have a bus of their own
+		return false
+	}
+
+	return videoBus(address) == videoBus(gb.dma.source)
+}
+
+// videoBus is whether an address is on the video bus rather than the external bus
+func videoBus(address uint16) (video bool) {
+	return address >= 0x8000 && address < 0xA000
+}
@@ -0,0 +1,126 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestDMA returns a GameBoy with a recognizable page at 0xC100
+func newTestDMA() (gb *GameBoy) {
+	gb = &GameBoy{}
+	for i := 0; i < 0x100; i++ {
+		gb.wram[0x100+i] = uint8(i)
+	}
+
+	return gb
+}
+
+func TestDMATransfer(t *testing.T) {
+	gb := newTestDMA()
+	gb.oam[0] = 0x42
+
+	gb.WriteMemory(DMA, 0xC1)
+	assert.Equal(t, uint8(0xC1), gb.ReadMemory(DMA))
+
+	// a cycle of setup, OAM is still there
+	ticks(gb, 1)
+	assert.Equal(t, uint8(0x42), gb.ReadMemory(0xFE00))
+
+	ticks(gb, 1)
+	assert.Equal(t, uint8(0x00), gb.oam[0])
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0xFE00))
+
+	gb.WriteMemory(0xFE00, 0x42)
+	assert.Equal(t, uint8(0x00), gb.oam[0], "OAM ignores writes")
+
+	ticks(gb, dmaLength-1)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0xFE9F), "the last byte is still being copied")
+
+	ticks(gb, 1)
+	assert.Equal(t, uint8(0x9F), gb.ReadMemory(0xFE9F))
+	assert.Equal(t, gb.wram[0x100:0x1A0], gb.oam[:])
+}
+
+func TestDMABusConflicts(t *testing.T) {
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(newTestROM(0x00, 0x00, 0x00)))
+	for i := 0; i < 0x100; i++ {
+		gb.wram[0x100+i] = uint8(i)
+	}
+
+	// keep the PPU out of VRAM
+	gb.WriteMemory(LCDC, 0)
+	gb.vram[0] = 0x11
+	gb.hram[0] = 0x22
+
+	gb.WriteMemory(DMA, 0xC1)
+	ticks(gb, 1+0x11)
+
+	// the external bus is busy with the byte DMA is copying
+	assert.Equal(t, uint8(0x10), gb.ReadMemory(0x0150))
+	assert.Equal(t, uint8(0x10), gb.ReadMemory(0xC000))
+	assert.Equal(t, uint8(0x10), gb.ReadMemory(0xE000))
+
+	gb.WriteMemory(0xC000, 0x33)
+	assert.Equal(t, uint8(0x00), gb.wram[0])
+
+	// the video bus, I/O and HRAM are not
+	assert.Equal(t, uint8(0x11), gb.ReadMemory(0x8000))
+	assert.Equal(t, uint8(0x22), gb.ReadMemory(0xFF80))
+	assert.Equal(t, uint8(0xC1), gb.ReadMemory(DMA))
+
+	// copying from VRAM takes the video bus instead
+	gb.vram[0x10] = 0x44
+	gb.WriteMemory(DMA, 0x80)
+	ticks(gb, 1+0x11)
+	assert.Equal(t, uint8(0x44), gb.ReadMemory(0x8000))
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(0xC000))
+}
+
+func TestDMARestart(t *testing.T) {
+	gb := newTestDMA()
+	for i := 0; i < 0x100; i++ {
+		gb.wram[0x200+i] = uint8(0xFF - i)
+	}
+
+	gb.WriteMemory(DMA, 0xC1)
+	ticks(gb, 50)
+
+	// the first transfer keeps OAM busy while the second one sets up
+	gb.WriteMemory(DMA, 0xC2)
+	ticks(gb, 1)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0xFE00))
+	assert.Equal(t, uint8(49), gb.oam[49])
+
+	ticks(gb, dmaLength)
+	assert.Equal(t, gb.wram[0x200:0x2A0], gb.oam[:])
+}
+
+func TestDMAEchoSource(t *testing.T) {
+	gb := newTestDMA()
+
+	gb.WriteMemory(DMA, 0xE1)
+	ticks(gb, 1+dmaLength)
+
+	assert.Equal(t, gb.wram[0x100:0x1A0], gb.oam[:])
+}
+
+func TestDMAFromHRAM(t *testing.T) {
+	gb := newTestDMA()
+	copy(gb.hram[:], []uint8{
+		0x3E, 0xC1, // LD A, 0xC1
+		0xE0, 0x46, // LD (0xFF00 + DMA), A
+		0x3E, 0x28, // LD A, 40
+		0x3D,       // DEC A
+		0x20, 0xFD, // JR NZ, -3
+		0x18, 0xFE, // JR -2
+	})
+	gb.pc = 0xFF80
+
+	for gb.pc != 0xFF89 {
+		gb.RunInstruction()
+	}
+
+	assert.Equal(t, gb.wram[0x100:0x1A0], gb.oam[:])
+}
@@ -0,0 +1,283 @@
+package goboy
+
+const (
+	fifoWarmUp      = 6 // dots spent on the first tile fetch of a line, which is thrown away
+	spriteFetchDots = 6 // dots a sprite fetch stalls drawing for, at least
+)
+
+// objPixel is a pixel in the sprite FIFO
+type objPixel struct {
+	index    uint8 // color index, 0 is transparent
+	palette  bool  // OBP1 instead of OBP0
+	priority bool  // background colors 1-3 are drawn over it
+}
+
+// fifo is the state of the accurate PPU's pixel pipeline for the current line.
+// The background fetcher fills the background FIFO 8 pixels at a time, one is
+// shifted out to the LCD per dot, and sprites are mixed in from their own
+// FIFO. Anything that stalls the pipeline makes mode 3 longer.
+// see https://gbdev.io/pandocs/pixel_fifo.html
+type fifo struct {
+	active bool // the current line is drawn by the FIFO
+
+	bg     [8]uint8    // background color indices
+	bgHead int         // next pixel to shift out of bg
+	bgLen  int         // pixels left in bg
+	obj    [8]objPixel // sprite pixels lined up with the first 8 of bg
+
+	fetchStep int   // 2 dots each for the tile, low byte and high byte, then push
+	fetchX    uint8 // tile column being fetched, relative to SCX or the window
+	tile      uint8 // fetched tile index
+	lo, hi    uint8 // fetched tile row
+
+	x       int // next pixel on the LCD
+	discard int // pixels to drop before the first one that is shown
+	stall   int // dots left before anything moves again, the warm up or a sprite fetch
+
+	window      bool // fetching window tiles
+	windowDrawn bool // the window was started on this line
+
+	sprites    []sprite // this line's sprites, by priority
+	fetched    [10]bool // sprites already in the sprite FIFO
+	considered uint64   // bit per tile a sprite fetch already waited for, window tiles from bit 32, see spritePenalty
+	pending    *sprite  // sprite being fetched, mixed in when the stall ends
+}
+
+// startFIFO sets up the pipeline at the start of mode 3
+func (gb *GameBoy) startFIFO() {
+	p := &gb.ppu
+
+	if p.line == p.wy {
+		p.windowTriggered = true
+	}
+
+	p.fifo = fifo{
+		active:  true,
+		discard: int(p.scx & 0x07),
+		stall:   fifoWarmUp,
+		sprites: gb.scanOAM(),
+	}
+}
+
+// fifoDot runs the pipeline for a dot, returning true once the line is done
+func (gb *GameBoy) fifoDot() (done bool) {
+	p := &gb.ppu
+	f := &p.fifo
+
+	if f.x == ScreenWidth {
+		if f.windowDrawn {
+			p.windowLine++
+		}
+
+		return true
+	}
+
+	if f.stall > 0 {
+		f.stall--
+
+		if f.stall == 0 && f.pending != nil {
+			gb.mixSprite(*f.pending)
+			f.pending = nil
+		}
+
+		return false
+	}
+
+	if gb.windowStarts() {
+		// the window replaces whatever background was already fetched
+		f.window, f.windowDrawn = true, true
+		f.bgLen, f.fetchStep, f.fetchX = 0, 0, 0
+
+		if p.wx < 7 {
+			f.discard = 7 - int(p.wx)
+		}
+	}
+
+	if p.lcdc&lcdcOBJEnable != 0 && f.discard == 0 {
+		for i := range f.sprites {
+			s := &f.sprites[i]
+			if f.fetched[i] || s.x >= ScreenWidth+8 || int(s.x)-8 > f.x {
+				continue
+			}
+
+			f.fetched[i] = true
+			f.pending = s
+			f.stall = gb.spritePenalty(*s) - 1
+
+			if f.stall == 0 {
+				gb.mixSprite(*s)
+				f.pending = nil
+			}
+
+			return false
+		}
+	}
+
+	gb.fetcherDot()
+
+	if f.bgLen > 0 {
+		gb.shiftPixel()
+	}
+
+	return false
+}
+
+// windowStarts is true when the window should take over from the background
+// at the current pixel
+func (gb *GameBoy) windowStarts() (starts bool) {
+	p := &gb.ppu
+	f := &p.fifo
+
+	if f.window || p.lcdc&lcdcWindowEnable == 0 || !p.windowTriggered || p.wx > 166 {
+		return false
+	}
+
+	if p.wx < 7 {
+		return f.x == 0
+	}
+
+	return f.x+7 == int(p.wx)
+}
+
+// spritePenalty is how many dots fetching a sprite takes. On top of the fetch
+// itself it waits for the background fetcher to get through the tile under
+// the sprite's leftmost pixel, unless an earlier sprite already waited for it.
+func (gb *GameBoy) spritePenalty(s sprite) (dots int) {
+	p := &gb.ppu
+	f := &p.fifo
+
+	x := int(s.x) - 8
+	if f.window {
+		x -= int(p.wx) - 7
+	} else {
+		x += int(p.scx & 0x07)
+	}
+
+	// floor division, the leftmost pixel may be left of the screen
+	tile := (x + 8) / 8
+	if f.window {
+		tile += 32
+	}
+
+	dots = spriteFetchDots
+
+	if f.considered&(1<<tile) == 0 {
+		f.considered |= 1 << tile
+
+		if right := 7 - (x+8)%8 - 2; right > 0 {
+			dots += right
+		}
+	}
+
+	return dots
+}
+
+// mixSprite adds a fetched sprite to the sprite FIFO, under any pixels from
+// higher priority sprites already there
+func (gb *GameBoy) mixSprite(s sprite) {
+	p := &gb.ppu
+	f := &p.fifo
+
+	lo, hi := gb.spriteRow(s)
+
+	for px := 0; px < 8; px++ {
+		slot := int(s.x) - 8 + px - f.x
+		if slot < 0 || slot >= len(f.obj) || f.obj[slot].index != 0 {
+			continue
+		}
+
+		tx := uint8(px)
+		if s.attrs&attrFlipX != 0 {
+			tx = 7 - tx
+		}
+
+		f.obj[slot] = objPixel{
+			index:    tileColor(lo, hi, tx),
+			palette:  s.attrs&attrPalette != 0,
+			priority: s.attrs&attrPriority != 0,
+		}
+	}
+}
+
+// fetcherDot advances the background fetcher by a dot
+func (gb *GameBoy) fetcherDot() {
+	p := &gb.ppu
+	f := &p.fifo
+
+	// the row within the map, read as late as possible like the hardware
+	y := p.line + p.scy
+	highMap := p.lcdc&lcdcBGTileMap != 0
+	column := (p.scx/8 + f.fetchX) & 0x1F
+
+	if f.window {
+		y = p.windowLine
+		highMap = p.lcdc&lcdcWindowTileMap != 0
+		column = f.fetchX & 0x1F
+	}
+
+	unsigned := p.lcdc&lcdcTileData != 0
+
+	switch f.fetchStep {
+	case 1:
+		f.tile = gb.mapTile(highMap, column, y/8)
+	case 3:
+		f.lo, _ = gb.tileRow(f.tile, y%8, unsigned)
+	case 5:
+		_, f.hi = gb.tileRow(f.tile, y%8, unsigned)
+	}
+
+	if f.fetchStep < 6 {
+		f.fetchStep++
+		return
+	}
+
+	// pushing waits for the FIFO to run dry
+	if f.bgLen > 0 {
+		return
+	}
+
+	for i := range f.bg {
+		f.bg[i] = tileColor(f.lo, f.hi, uint8(i))
+	}
+
+	f.bgHead, f.bgLen = 0, len(f.bg)
+	f.fetchStep = 0
+	f.fetchX++
+}
+
+// shiftPixel shifts a pixel out of the FIFOs to the LCD, using the palettes as
+// they are right now
+func (gb *GameBoy) shiftPixel() {
+	p := &gb.ppu
+	f := &p.fifo
+
+	index := f.bg[f.bgHead]
+	f.bgHead++
+	f.bgLen--
+
+	if f.discard > 0 {
+		f.discard--
+		return
+	}
+
+	obj := f.obj[0]
+	copy(f.obj[:], f.obj[1:])
+	f.obj[len(f.obj)-1] = objPixel{}
+
+	shade := palette(p.bgp, index)
+	if p.lcdc&lcdcBGEnable == 0 {
+		index, shade = 0, 0
+	}
+
+	if obj.index != 0 && p.lcdc&lcdcOBJEnable != 0 && !(obj.priority && index != 0) {
+		pal := p.obp0
+		if obj.palette {
+			pal = p.obp1
+		}
+
+		shade = palette(pal, obj.index)
+	}
+
+	p.back[int(p.line)*ScreenWidth+f.x] = shade
+	f.x++
+}
@@ -0,0 +1,175 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestFIFO returns a GameBoy drawing with the FIFO, with identity palettes
+// and the given LCDC bits. The LCD is still off so everything can be set up.
+func newTestFIFO(lcdc uint8) (gb *GameBoy) {
+	gb = newTestRenderer(lcdc)
+	gb.ppu.lcdc &^= lcdcEnable
+	gb.AccuratePPU = true
+
+	return gb
+}
+
+// drawLine runs the PPU dot by dot until the given line has been drawn,
+// returning how long mode 3 took
+func drawLine(gb *GameBoy, line uint8) (length int) {
+	if gb.ppu.lcdc&lcdcEnable == 0 {
+		gb.WriteMemory(LCDC, gb.ppu.lcdc|lcdcEnable)
+	}
+
+	for gb.ppu.line != line || gb.ppu.mode != modeDrawing {
+		gb.ppuDot()
+	}
+
+	for gb.ppu.mode == modeDrawing {
+		gb.ppuDot()
+		length++
+	}
+
+	return length
+}
+
+func TestFIFOMode3Length(t *testing.T) {
+	cases := []struct {
+		name   string
+		lcdc   uint8
+		setup  func(gb *GameBoy)
+		length int
+	}{
+		{"plain", 0, func(gb *GameBoy) {}, 172},
+		{"fine scroll", 0, func(gb *GameBoy) { gb.ppu.scx = 3 }, 175},
+		{"coarse scroll", 0, func(gb *GameBoy) { gb.ppu.scx = 8 }, 172},
+		{"window", lcdcWindowEnable, func(gb *GameBoy) { gb.ppu.wx = 7 + 80 }, 178},
+		{"window off screen", lcdcWindowEnable, func(gb *GameBoy) { gb.ppu.wx = 167 }, 172},
+		{"sprite at 0", lcdcOBJEnable, func(gb *GameBoy) {
+			copy(gb.oam[:], []uint8{16 + 1, 8, 0, 0})
+		}, 183},
+		{"sprite mid tile", lcdcOBJEnable, func(gb *GameBoy) {
+			copy(gb.oam[:], []uint8{16 + 1, 8 + 5, 0, 0})
+		}, 178},
+		{"sprites sharing a tile", lcdcOBJEnable, func(gb *GameBoy) {
+			copy(gb.oam[:], []uint8{16 + 1, 8, 0, 0, 16 + 1, 10, 0, 0})
+		}, 189},
+		{"sprites disabled", 0, func(gb *GameBoy) {
+			copy(gb.oam[:], []uint8{16 + 1, 8, 0, 0})
+		}, 172},
+		{"sprite off screen", lcdcOBJEnable, func(gb *GameBoy) {
+			copy(gb.oam[:], []uint8{16 + 1, 168, 0, 0})
+		}, 172},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gb := newTestFIFO(tc.lcdc)
+			tc.setup(gb)
+
+			assert.Equal(t, tc.length, drawLine(gb, 1))
+		})
+	}
+}
+
+func TestFIFOMatchesScanline(t *testing.T) {
+	setup := func(gb *GameBoy) {
+		for i := 0; i < 4; i++ {
+			solidTile(gb, 0x8000+uint16(i)*16, uint8(i))
+		}
+
+		// a tile with a different color in each column
+		gb.vram[0x40], gb.vram[0x41] = 0b0101_0101, 0b0011_0011
+
+		for i := 0; i < 0x800; i++ {
+			gb.vram[0x1800+i] = uint8(i*7) % 5
+		}
+
+		copy(gb.oam[:], []uint8{
+			20, 12, 4, 0,
+			24, 15, 2, attrFlipX,
+			30, 60, 4, attrPriority,
+			40, 100, 3, attrPalette,
+			40, 100, 1, 0,
+			60, 2, 4, 0,
+		})
+
+		gb.ppu.scx, gb.ppu.scy = 13, 3
+		gb.ppu.wy, gb.ppu.wx = 50, 90
+		gb.ppu.obp1 = 0x1B
+	}
+
+	lcdc := lcdcEnable | lcdcBGEnable | lcdcOBJEnable | lcdcTileData | lcdcWindowEnable | lcdcWindowTileMap
+
+	scanline := newTestCPU(0x18, 0xFE) // JR -2
+	setup(scanline)
+	scanline.WriteMemory(LCDC, lcdc)
+	scanline.RunFrame()
+
+	accurate := newTestCPU(0x18, 0xFE)
+	accurate.AccuratePPU = true
+	setup(accurate)
+	accurate.WriteMemory(LCDC, lcdc)
+	accurate.RunFrame()
+
+	for y := 0; y < ScreenHeight; y++ {
+		row := y * ScreenWidth
+		assert.Equal(t, scanline.ppu.back[row:row+ScreenWidth], accurate.ppu.back[row:row+ScreenWidth], "line %d", y)
+	}
+}
+
+func TestFIFOMidLineChanges(t *testing.T) {
+	gb := newTestFIFO(lcdcTileData)
+	solidTile(gb, 0x8010, 1)
+	for i := 0; i < 32*32; i++ {
+		gb.vram[0x1800+i] = 1
+	}
+
+	gb.WriteMemory(LCDC, gb.ppu.lcdc|lcdcEnable)
+	for gb.ppu.line != 1 || gb.ppu.mode != modeDrawing {
+		gb.ppuDot()
+	}
+
+	// mode 3 started a dot ago, the first pixel comes out 12 dots in and then
+	// one per dot
+	for i := 1; i < 12+80; i++ {
+		gb.ppuDot()
+	}
+
+	gb.ppu.bgp = 0b0000_1000
+	for gb.ppu.mode == modeDrawing {
+		gb.ppuDot()
+	}
+
+	line := gb.ppu.back[ScreenWidth : 2*ScreenWidth]
+	assert.Equal(t, uint8(1), line[79])
+	assert.Equal(t, uint8(2), line[80])
+	assert.Equal(t, uint8(2), line[159])
+
+	// the scanline renderer only sees the palette at the start of the line
+	gb.AccuratePPU = false
+	gb.ppu.bgp = 0xE4
+	for gb.ppu.line != 2 || gb.ppu.mode != modeDrawing {
+		gb.ppuDot()
+	}
+
+	gb.ppu.bgp = 0b0000_1000
+	line = gb.ppu.back[2*ScreenWidth : 3*ScreenWidth]
+	assert.Equal(t, uint8(1), line[159])
+}
+
+func TestFIFOWindowLineCounter(t *testing.T) {
+	gb := newTestFIFO(lcdcTileData | lcdcWindowEnable)
+	gb.ppu.wy, gb.ppu.wx = 1, 7
+
+	drawLine(gb, 1)
+	drawLine(gb, 2)
+	assert.Equal(t, uint8(2), gb.ppu.windowLine)
+
+	// no window, no count
+	gb.ppu.wx = 200
+	drawLine(gb, 3)
+	assert.Equal(t, uint8(2), gb.ppu.windowLine)
+}
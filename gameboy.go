@@ -1,10 +1,11 @@
 package goboy
 
+import "time"
+
 type GameBoy struct {
-	memory [65536]uint8 // 64K RAM
-	pc     uint16       // Program Counter - The memory address of the next instruction to fetch
-	sp     uint16       // Stack Pointer - the memory address of the top of the stack
-	f      uint8        // Flags
+	pc uint16 // Program Counter - The memory address of the next instruction to fetch
+	sp uint16 // Stack Pointer - the memory address of the top of the stack
+	f  uint8  // Flags
 
 	// General purpose registers, f is reserved for the flags register
 	a uint8
@@ -15,9 +16,54 @@ type GameBoy struct {
 	h uint8
 	l uint8
 
-	tickCount uint64 // Number of elapsed ticks since the start of execution
+	ime       bool // Interrupt Master Enable - whether interrupts can be serviced
+	eiPending bool // EI was executed, IME is set after the next instruction
+	branched  bool // the current instruction jumped, see jump
+	halted    bool // HALT was executed, the CPU idles until an interrupt is pending
+	haltBug   bool // HALT was skipped with IME off, the next byte is fetched twice
+	stopped   bool // STOP was executed, the CPU idles until a button is pressed
+
+	tickCount uint64 // Number of elapsed T-cycles since the start of execution
+	frameDone bool   // VBlank started, RunFrame returns at the end of the instruction
 
 	romData []uint8
+	header  *CartridgeHeader
+	mbc     mbc     // memory bank controller, owns the cartridge RAM
+	bootROM []uint8 // optional, overlays 0x0000-0x00FF until 0xFF50 is written
+
+	bootMapped bool // whether the boot ROM currently overlays the cartridge
+	ramDirty   bool // battery backed cartridge RAM changed since it was last loaded or saved
+
+	// memory regions, see ReadMemory for the full memory map
+	vram [0x2000]uint8 // Video RAM - 0x8000-0x9FFF
+	wram [0x2000]uint8 // Work RAM - 0xC000-0xDFFF, echoed at 0xE000-0xFDFF
+	oam  [0x00A0]uint8 // Object Attribute Memory - 0xFE00-0xFE9F
+	io   [0x0080]uint8 // I/O registers - 0xFF00-0xFF7F
+	hram [0x007F]uint8 // High RAM - 0xFF80-0xFFFE
+	ie   uint8         // Interrupt Enable register - 0xFFFF
+
+	joypad  joypad // JOYP
+	buttons Button // buttons held down, these outlive a Reset
+	serial  serial // SB and SC
+	timer   timer  // DIV, TIMA, TMA and TAC
+	ppu     ppu    // LCD registers and the PPU's progress through the frame
+	dma     dma    // OAM DMA transfer
+	apu     apu    // sound registers, channels and the samples waiting for ReadAudio
+
+	Model    Model // hardware revision, decides the post-boot state when skipping the boot ROM
+	SkipBoot bool  // start at 0x0100 in the post-boot state even if a boot ROM is loaded
+
+	// AccuratePPU draws through a model of the pixel FIFOs, which is slower but
+	// gets mode 3's length and mid-line register changes right
+	AccuratePPU bool
+
+	SampleRate   int          // stereo samples per second ReadAudio produces, DefaultSampleRate when 0
+	AudioQuality AudioQuality // how ReadAudio's samples are made, band-limited by default
+
+	Link LinkCable // what is plugged into the serial port, nothing when nil
+
+	Now    func() time.Time // wall clock for cartridge real-time clocks, time.Now when nil
+	Rumble func(on bool)    // called when a rumble cartridge turns its motor on or off
 
 	Debug bool // not part of the GameBoy spec, useful for debugging
 }
@@ -29,6 +75,41 @@ const (
 	MaskCarryFlag       uint8 = 0b0001_0000 // carry flag mask - C - set if there was a carry from the result
 )
 
+// jump sets the address of the next instruction. It also marks the current
+// instruction as having branched, which conditional instructions take longer for.
+func (gb *GameBoy) jump(address uint16) {
+	gb.pc = address
+	gb.branched = true
+}
+
+// condition evaluates table cc: NZ, Z, NC, C
+func (gb *GameBoy) condition(cc uint8) (met bool) {
+	switch cc {
+	case 0: // NZ
+		return !gb.flag(MaskZeroFlag)
+	case 1: // Z
+		return gb.flag(MaskZeroFlag)
+	case 2: // NC
+		return !gb.flag(MaskCarryFlag)
+	default: // C
+		return gb.flag(MaskCarryFlag)
+	}
+}
+
+// setFlag sets the flags in mask when set is true, clears them otherwise
+func (gb *GameBoy) setFlag(mask uint8, set bool) {
+	if set {
+		gb.f |= mask
+	} else {
+		gb.f &= ^mask
+	}
+}
+
+// flag is whether any of the flags in mask are set
+func (gb *GameBoy) flag(mask uint8) (set bool) {
+	return gb.f&mask != 0
+}
+
 func mergeBytes(msb uint8, lsb uint8) uint16 {
 	return (uint16(msb) << 8) | uint16(lsb)
 }
@@ -70,3 +151,19 @@ func (gb *GameBoy) setHL(x uint16) {
 func (gb *GameBoy) readHL() (bc uint16) {
 	return mergeBytes(gb.h, gb.l)
 }
+
+// now is the wall clock time according to Now
+func (gb *GameBoy) now() (t time.Time) {
+	if gb.Now != nil {
+		return gb.Now()
+	}
+
+	return time.Now()
+}
+
+// rumble forwards a rumble cartridge's motor state to Rumble
+func (gb *GameBoy) rumble(on bool) {
+	if gb.Rumble != nil {
+		gb.Rumble(on)
+	}
+}
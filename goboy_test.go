@@ -10,13 +10,20 @@ import (
 
 func TestDebug(t *testing.T) {
 	// utility test for debugging goboy internals without WASM
-	rom, err := os.ReadFile("cmd/goboy-wasm/dist/DMG_ROM.bin")
+	boot, err := os.ReadFile("cmd/goboy-wasm/dist/DMG_ROM.bin")
+	if os.IsNotExist(err) {
+		// the boot ROM can't be distributed with goboy
+		t.Skip("cmd/goboy-wasm/dist/DMG_ROM.bin not found")
+	}
 	assert.NoError(t, err)
 
 	gb := &goboy.GameBoy{}
 	gb.Debug = true
 
-	gb.LoadROM(rom)
+	err = gb.LoadBootROM(boot)
+	assert.NoError(t, err)
+
+	gb.Reset()
 
 	gb.RunFrame()
 
@@ -0,0 +1,72 @@
+package goboy
+
+// interrupt sources, each is a bit in both IE and IF. Lower bits have priority.
+const (
+	interruptVBlank uint8 = 1 << iota
+	interruptSTAT
+	interruptTimer
+	interruptSerial
+	interruptJoypad
+
+	interruptMask uint8 = 0x1F // IF only has 5 bits, the rest read as 1
+)
+
+// interruptVector is the address the first interrupt handler lives at, each
+// following source's handler is 8 bytes further along
+const interruptVector = 0x0040
+
+// requestInterrupt sets the source's bit in IF, it is serviced once the CPU
+// is ready for it. This is how peripherals signal the CPU.
+func (gb *GameBoy) requestInterrupt(source uint8) {
+	gb.io[IF-0xFF00] |= source & interruptMask
+}
+
+// pendingInterrupts are the requested interrupts that are also enabled
+func (gb *GameBoy) pendingInterrupts() (pending uint8) {
+	return gb.ie & gb.io[IF-0xFF00] & interruptMask
+}
+
+// handleInterrupts wakes the CPU from HALT and dispatches the highest priority
+// pending interrupt if IME allows it. Returns true if the CPU spent its step
+// doing so instead of executing an instruction.
+func (gb *GameBoy) handleInterrupts() (handled bool) {
+	pending := gb.pendingInterrupts()
+	if pending == 0 {
+		return false
+	}
+
+	// any pending interrupt ends HALT, even if it can't be serviced
+	woke := gb.halted
+	if woke {
+		gb.halted = false
+		gb.tick()
+	}
+
+	if !gb.ime {
+		return woke
+	}
+
+	for bit := uint8(0); bit < 5; bit++ {
+		source := uint8(1) << bit
+		if pending&source == 0 {
+			continue
+		}
+
+		gb.ime = false
+		gb.eiPending = false
+		gb.io[IF-0xFF00] &^= source
+
+		// two wait states, pushing PC and jumping take the other 3 M-cycles
+		gb.tick()
+		gb.tick()
+		gb.PushStack(gb.pc)
+		gb.pc = interruptVector + uint16(bit)*8
+		gb.tick()
+
+		gb.debugLnF("interrupt %d, jumping to %.4X", bit, gb.pc)
+
+		break
+	}
+
+	return true
+}
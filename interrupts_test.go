@@ -0,0 +1,129 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterruptRegisters(t *testing.T) {
+	gb := &GameBoy{}
+
+	assert.Equal(t, uint8(0xE0), gb.ReadMemory(IF))
+
+	gb.WriteMemory(IF, 0xFF)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(IF))
+	assert.Equal(t, interruptMask, gb.io[IF-0xFF00])
+
+	gb.WriteMemory(IE, 0xFF)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(IE))
+
+	gb.WriteMemory(IF, 0)
+	gb.requestInterrupt(interruptTimer)
+	assert.Equal(t, uint8(0xE4), gb.ReadMemory(IF))
+}
+
+func TestInterruptDispatch(t *testing.T) {
+	gb := newTestCPU(
+		0xFB, // EI
+		0x00, // NOP
+		0x00, // NOP
+	)
+	gb.ie = interruptVBlank | interruptTimer | interruptJoypad
+	gb.requestInterrupt(interruptTimer)
+	gb.requestInterrupt(interruptJoypad)
+	gb.requestInterrupt(interruptSerial) // not enabled
+
+	// EI, then the NOP after it still runs before IME is set
+	gb.RunInstruction()
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC002), gb.pc)
+	assert.True(t, gb.ime)
+
+	ticks := gb.tickCount
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0x0050), gb.pc, "timer beats joypad")
+	assert.Equal(t, uint16(0xC002), gb.readMemory16(gb.sp))
+	assert.Equal(t, uint64(5*4), gb.tickCount-ticks)
+	assert.False(t, gb.ime)
+	assert.Equal(t, interruptSerial|interruptJoypad, gb.io[IF-0xFF00])
+
+	// RETI returns and immediately services the joypad interrupt
+	gb.pc = 0xC100
+	gb.wram[0x0100] = 0xD9 // RETI
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC002), gb.pc)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0x0060), gb.pc)
+	assert.Equal(t, interruptSerial, gb.io[IF-0xFF00])
+}
+
+func TestHaltWakeUp(t *testing.T) {
+	gb := newTestCPU(
+		0xFB, // EI
+		0x76, // HALT
+		0x00, // NOP
+	)
+	gb.ie = interruptVBlank
+
+	gb.RunInstruction()
+	gb.RunInstruction()
+	assert.True(t, gb.halted)
+
+	for i := 0; i < 10; i++ {
+		gb.RunInstruction()
+	}
+	assert.True(t, gb.halted)
+	assert.Equal(t, uint16(0xC002), gb.pc)
+
+	gb.requestInterrupt(interruptVBlank)
+	gb.RunInstruction()
+	assert.False(t, gb.halted)
+	assert.Equal(t, uint16(0x0040), gb.pc)
+	assert.Equal(t, uint16(0xC002), gb.readMemory16(gb.sp))
+}
+
+func TestHaltWithoutIME(t *testing.T) {
+	gb := newTestCPU(
+		0x76, // HALT
+		0x04, // INC B
+	)
+	gb.ie = interruptVBlank
+
+	gb.RunInstruction()
+	assert.True(t, gb.halted)
+
+	// wakes up, but carries on after HALT instead of servicing the interrupt
+	gb.requestInterrupt(interruptVBlank)
+	gb.RunInstruction()
+	assert.False(t, gb.halted)
+	assert.Equal(t, uint16(0xC001), gb.pc)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(1), gb.b)
+	assert.Equal(t, uint16(0xC002), gb.pc)
+}
+
+func TestHaltBug(t *testing.T) {
+	gb := newTestCPU(
+		0x76,       // HALT
+		0x3E, 0x14, // LD A, 0x14
+	)
+	gb.ie = interruptVBlank
+	gb.requestInterrupt(interruptVBlank)
+
+	gb.RunInstruction()
+	assert.False(t, gb.halted)
+	assert.Equal(t, uint16(0xC001), gb.pc)
+
+	// the opcode is read twice, so it becomes its own immediate
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x3E), gb.a)
+	assert.Equal(t, uint16(0xC002), gb.pc)
+
+	// and the old immediate runs as INC D
+	gb.RunInstruction()
+	assert.Equal(t, uint8(1), gb.d)
+	assert.Equal(t, uint16(0xC003), gb.pc)
+}
@@ -0,0 +1,86 @@
+package goboy
+
+// Button is one or more of the GameBoy's buttons, as a bit mask
+type Button uint8
+
+// The buttons. The directions match the lower nibble of JOYP when the d-pad is
+// selected, the rest match it shifted down when the buttons are selected.
+const (
+	ButtonRight Button = 1 << iota
+	ButtonLeft
+	ButtonUp
+	ButtonDown
+	ButtonA
+	ButtonB
+	ButtonSelect
+	ButtonStart
+)
+
+// JOYP select bits, a 0 selects the group
+const (
+	joypSelectDPad    uint8 = 1 << 4
+	joypSelectButtons uint8 = 1 << 5
+)
+
+// joypad is the state behind JOYP
+// see https://gbdev.io/pandocs/Joypad_Input.html
+type joypad struct {
+	selection uint8 // bits 4 and 5 of JOYP
+	lines     uint8 // the lower nibble of JOYP as of the last change, for edge detection
+}
+
+// Press holds down the given buttons, leaving the others as they are
+func (gb *GameBoy) Press(buttons Button) {
+	gb.SetButtons(gb.buttons | buttons)
+}
+
+// Release lets go of the given buttons, leaving the others as they are
+func (gb *GameBoy) Release(buttons Button) {
+	gb.SetButtons(gb.buttons &^ buttons)
+}
+
+// SetButtons sets which buttons are held down, all of them at once
+func (gb *GameBoy) SetButtons(buttons Button) {
+	gb.buttons = buttons
+	gb.updateJoypad()
+}
+
+// joypadLines is the active low lower nibble of JOYP, the held buttons of
+// whichever groups are selected
+func (gb *GameBoy) joypadLines() (lines uint8) {
+	var held uint8
+
+	if gb.joypad.selection&joypSelectDPad == 0 {
+		held |= uint8(gb.buttons) & 0x0F
+	}
+
+	if gb.joypad.selection&joypSelectButtons == 0 {
+		held |= uint8(gb.buttons) >> 4
+	}
+
+	return ^held & 0x0F
+}
+
+// updateJoypad requests the joypad interrupt when any line goes from high to
+// low, which is also what brings the CPU out of STOP
+func (gb *GameBoy) updateJoypad() {
+	lines := gb.joypadLines()
+
+	if gb.joypad.lines&^lines != 0 {
+		gb.requestInterrupt(interruptJoypad)
+		gb.stopped = false
+	}
+
+	gb.joypad.lines = lines
+}
+
+func (gb *GameBoy) readJoypad() (value byte) {
+	return 0xC0 | gb.joypad.selection | gb.joypadLines()
+}
+
+func (gb *GameBoy) writeJoypad(value byte) {
+	// only the select bits are writable, selecting a group with a button
+	// held counts as a press
+	gb.joypad.selection = value & (joypSelectDPad | joypSelectButtons)
+	gb.updateJoypad()
+}
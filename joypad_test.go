@@ -0,0 +1,97 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoypadSelect(t *testing.T) {
+	gb := &GameBoy{}
+	gb.Reset()
+
+	gb.Press(ButtonRight | ButtonA | ButtonStart)
+
+	// nothing selected
+	gb.WriteMemory(JOYP, 0x30)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(JOYP))
+
+	// d-pad
+	gb.WriteMemory(JOYP, 0x20)
+	assert.Equal(t, uint8(0xEE), gb.ReadMemory(JOYP))
+
+	// buttons
+	gb.WriteMemory(JOYP, 0x10)
+	assert.Equal(t, uint8(0xD6), gb.ReadMemory(JOYP))
+
+	// both, the groups are ANDed
+	gb.WriteMemory(JOYP, 0x00)
+	assert.Equal(t, uint8(0xC6), gb.ReadMemory(JOYP))
+
+	gb.Release(ButtonA)
+	assert.Equal(t, uint8(0xC6), gb.ReadMemory(JOYP))
+
+	gb.Release(ButtonRight)
+	assert.Equal(t, uint8(0xC7), gb.ReadMemory(JOYP))
+
+	gb.SetButtons(ButtonDown | ButtonB)
+	assert.Equal(t, uint8(0xC5), gb.ReadMemory(JOYP))
+
+	// only the select bits are writable
+	gb.WriteMemory(JOYP, 0xFF)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(JOYP))
+}
+
+func TestJoypadInterrupt(t *testing.T) {
+	gb := &GameBoy{}
+	gb.Reset()
+
+	gb.WriteMemory(JOYP, 0x20)
+
+	// a button of a group that isn't selected doesn't change the lines
+	gb.Press(ButtonStart)
+	assert.Zero(t, gb.pendingInterrupts()&interruptJoypad)
+
+	gb.Press(ButtonUp)
+	assert.NotZero(t, gb.ReadMemory(IF)&interruptJoypad)
+
+	// releasing is a low to high transition
+	gb.WriteMemory(IF, 0)
+	gb.Release(ButtonUp)
+	assert.Zero(t, gb.ReadMemory(IF)&interruptJoypad)
+
+	// selecting a group with a button already held is a transition too
+	gb.WriteMemory(JOYP, 0x10)
+	assert.NotZero(t, gb.ReadMemory(IF)&interruptJoypad)
+}
+
+func TestJoypadReset(t *testing.T) {
+	// a boot ROM, so the reset leaves JOYP alone
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadBootROM(make([]byte, BootROMSize)))
+	gb.Reset()
+
+	// held through the reset, with nothing selected beforehand
+	gb.Press(ButtonA)
+	gb.WriteMemory(JOYP, 0x30)
+	gb.Reset()
+	gb.WriteMemory(IF, 0)
+
+	// the lines were already low when it came out of reset
+	gb.WriteMemory(JOYP, 0x00)
+	assert.Zero(t, gb.ReadMemory(IF)&interruptJoypad)
+}
+
+func TestJoypadWakesStop(t *testing.T) {
+	gb := &GameBoy{}
+	gb.Reset()
+
+	gb.WriteMemory(JOYP, 0x10)
+	gb.stopped = true
+
+	gb.Press(ButtonLeft)
+	assert.True(t, gb.stopped)
+
+	gb.Press(ButtonA)
+	assert.False(t, gb.stopped)
+}
@@ -0,0 +1,400 @@
+package goboy
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnsupportedCartridge is returned when loading a cartridge with hardware goboy doesn't emulate
+var ErrUnsupportedCartridge = errors.New("unsupported cartridge type")
+
+const (
+	romBankSize = 0x4000 // 16KiB
+	ramBankSize = 0x2000 // 8KiB
+)
+
+// mbc is a cartridge's memory bank controller, it decides what the CPU sees at
+// 0x0000-0x7FFF and 0xA000-0xBFFF. Writes to the ROM area never change the ROM,
+// they set the controller's registers instead.
+type mbc interface {
+	readROM(address uint16) (value uint8)
+	writeROM(address uint16, value uint8)
+	readRAM(address uint16) (value uint8)
+
+	// writeRAM reports whether the write changed what the cartridge keeps
+	writeRAM(address uint16, value uint8) (changed bool)
+
+	// externalRAM is the cartridge RAM as it's laid out in a .sav file
+	externalRAM() (ram []uint8)
+}
+
+// mapper is a family of memory bank controllers
+type mapper uint8
+
+const (
+	mapperNone mapper = iota
+	mapperMBC1
+	mapperMBC2
+	mapperMBC3
+	mapperMBC5
+)
+
+// cartridgeType describes the hardware on a cartridge, decoded from header byte 0x0147
+type cartridgeType struct {
+	mapper  mapper
+	ram     bool
+	battery bool
+	timer   bool
+	rumble  bool
+}
+
+var cartridgeTypes = map[uint8]cartridgeType{
+	0x00: {mapper: mapperNone},
+	0x01: {mapper: mapperMBC1},
+	0x02: {mapper: mapperMBC1, ram: true},
+	0x03: {mapper: mapperMBC1, ram: true, battery: true},
+	0x05: {mapper: mapperMBC2},
+	0x06: {mapper: mapperMBC2, battery: true},
+	0x0F: {mapper: mapperMBC3, battery: true, timer: true},
+	0x10: {mapper: mapperMBC3, ram: true, battery: true, timer: true},
+	0x11: {mapper: mapperMBC3},
+	0x12: {mapper: mapperMBC3, ram: true},
+	0x13: {mapper: mapperMBC3, ram: true, battery: true},
+	0x19: {mapper: mapperMBC5},
+	0x1A: {mapper: mapperMBC5, ram: true},
+	0x1B: {mapper: mapperMBC5, ram: true, battery: true},
+	0x1C: {mapper: mapperMBC5, rumble: true},
+	0x1D: {mapper: mapperMBC5, ram: true, rumble: true},
+	0x1E: {mapper: mapperMBC5, ram: true, battery: true, rumble: true},
+	0x08: {mapper: mapperNone, ram: true},
+	0x09: {mapper: mapperNone, ram: true, battery: true},
+}
+
+// HasBattery is whether the cartridge keeps its RAM (and clock) powered while it's unplugged
+func (h *CartridgeHeader) HasBattery() (battery bool) {
+	return cartridgeTypes[h.CartridgeType].battery
+}
+
+// newMBC builds the memory bank controller described by a cartridge header, now
+// is the wall clock used by cartridges with a real-time clock and rumble is
+// called when a rumble cartridge's motor turns on or off
+func newMBC(header *CartridgeHeader, rom []byte, now func() time.Time, rumble func(on bool)) (m mbc, err error) {
+	cart, ok := cartridgeTypes[header.CartridgeType]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnsupportedCartridge, "0x%.2X", header.CartridgeType)
+	}
+
+	var ram []uint8
+	if cart.ram {
+		size, err := header.RAMSize()
+		if err != nil {
+			return nil, err
+		}
+
+		ram = make([]uint8, size)
+	}
+
+	switch cart.mapper {
+	case mapperMBC1:
+		return &mbc1{
+			rom:       rom,
+			ram:       ram,
+			bank1:     1,
+			multicart: isMBC1Multicart(rom),
+		}, nil
+	case mapperMBC2:
+		return &mbc2{rom: rom, romBank: 1}, nil
+	case mapperMBC3:
+		m := &mbc3{rom: rom, ram: ram, romBank: 1}
+		if cart.timer {
+			m.rtc = &rtc{now: now, updated: now()}
+		}
+
+		return m, nil
+	case mapperMBC5:
+		m := &mbc5{rom: rom, ram: ram, romBank: 1}
+		if cart.rumble {
+			m.rumble = rumble
+		}
+
+		return m, nil
+	default:
+		return &romOnly{rom: rom, ram: ram}, nil
+	}
+}
+
+// bankOffset finds a byte in banked memory, wrapping banks past the end of the
+// chip like the unconnected address lines on a real cartridge would
+func bankOffset(size int, bankSize int, bank int, address uint16) (offset int) {
+	return (bank*bankSize + int(address)%bankSize) % size
+}
+
+// romOnly is a cartridge without a memory bank controller, 32KiB of ROM and an
+// optional 8KiB of RAM
+type romOnly struct {
+	rom []uint8
+	ram []uint8
+}
+
+func (m *romOnly) readROM(address uint16) (value uint8) {
+	if int(address) >= len(m.rom) {
+		return 0xFF
+	}
+
+	return m.rom[address]
+}
+
+func (m *romOnly) writeROM(address uint16, value uint8) {
+	// no registers to write to
+}
+
+func (m *romOnly) externalRAM() (ram []uint8) {
+	return m.ram
+}
+
+func (m *romOnly) readRAM(address uint16) (value uint8) {
+	if len(m.ram) == 0 {
+		return 0xFF
+	}
+
+	return m.ram[bankOffset(len(m.ram), ramBankSize, 0, address)]
+}
+
+func (m *romOnly) writeRAM(address uint16, value uint8) (changed bool) {
+	if len(m.ram) == 0 {
+		return false
+	}
+
+	return storeRAM(m.ram, bankOffset(len(m.ram), ramBankSize, 0, address), value)
+}
+
+// mbc1 supports up to 2MiB of ROM and 32KiB of RAM, see
+// https://gbdev.io/pandocs/MBC1.html
+type mbc1 struct {
+	rom []uint8
+	ram []uint8
+
+	ramEnabled bool
+	bank1      uint8 // 5 bit ROM bank number, 0x2000-0x3FFF
+	bank2      uint8 // 2 bit RAM bank number or upper ROM bank bits, 0x4000-0x5FFF
+	mode       uint8 // banking mode, 0x6000-0x7FFF
+
+	// MBC1M multicarts don't connect bit 4 of bank1, so bank2 selects one of
+	// four 256KiB games instead of the upper bits of a 2MiB ROM
+	multicart bool
+}
+
+// isMBC1Multicart detects MBC1M cartridges by the second game's header, the
+// cartridge header doesn't distinguish them
+func isMBC1Multicart(rom []byte) (multicart bool) {
+	if len(rom) != 0x100000 {
+		return false
+	}
+
+	logo := rom[0x10*romBankSize+0x0104 : 0x10*romBankSize+0x0134]
+
+	return bytes.Equal(logo, nintendoLogo[:])
+}
+
+func (m *mbc1) romBank(address uint16) (bank int) {
+	shift, low := 5, m.bank1
+	if m.multicart {
+		shift, low = 4, m.bank1&0x0F
+	}
+
+	if address < 0x4000 {
+		if m.mode == 0 {
+			return 0
+		}
+
+		// mode 1 lets bank2 switch the lower 16KiB too
+		return int(m.bank2) << shift
+	}
+
+	return int(m.bank2)<<shift | int(low)
+}
+
+func (m *mbc1) readROM(address uint16) (value uint8) {
+	return m.rom[bankOffset(len(m.rom), romBankSize, m.romBank(address), address)]
+}
+
+func (m *mbc1) writeROM(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		m.ramEnabled = value&0x0F == 0x0A
+	case address < 0x4000:
+		m.bank1 = value & 0x1F
+
+		// bank 0 can't be selected here, the check happens before the upper
+		// bits are added, which is why 0x20, 0x40 and 0x60 are unreachable
+		if m.bank1 == 0 {
+			m.bank1 = 1
+		}
+	case address < 0x6000:
+		m.bank2 = value & 0x03
+	default:
+		m.mode = value & 0x01
+	}
+}
+
+func (m *mbc1) ramOffset(address uint16) (offset int) {
+	bank := 0
+	if m.mode == 1 {
+		bank = int(m.bank2)
+	}
+
+	return bankOffset(len(m.ram), ramBankSize, bank, address)
+}
+
+func (m *mbc1) externalRAM() (ram []uint8) {
+	return m.ram
+}
+
+func (m *mbc1) readRAM(address uint16) (value uint8) {
+	if !m.ramEnabled || len(m.ram) == 0 {
+		return 0xFF
+	}
+
+	return m.ram[m.ramOffset(address)]
+}
+
+func (m *mbc1) writeRAM(address uint16, value uint8) (changed bool) {
+	if !m.ramEnabled || len(m.ram) == 0 {
+		return false
+	}
+
+	return storeRAM(m.ram, m.ramOffset(address), value)
+}
+
+// mbc2 supports up to 256KiB of ROM and has 512 4 bit values of RAM built in,
+// see https://gbdev.io/pandocs/MBC2.html
+type mbc2 struct {
+	rom []uint8
+	ram [0x0200]uint8 // only the lower nibbles are used
+
+	ramEnabled bool
+	romBank    uint8 // 4 bits
+}
+
+func (m *mbc2) readROM(address uint16) (value uint8) {
+	bank := 0
+	if address >= 0x4000 {
+		bank = int(m.romBank)
+	}
+
+	return m.rom[bankOffset(len(m.rom), romBankSize, bank, address)]
+}
+
+func (m *mbc2) writeROM(address uint16, value uint8) {
+	if address >= 0x4000 {
+		return
+	}
+
+	// both registers share 0x0000-0x3FFF, bit 8 of the address picks one
+	if address&0x0100 == 0 {
+		m.ramEnabled = value&0x0F == 0x0A
+		return
+	}
+
+	m.romBank = value & 0x0F
+	if m.romBank == 0 {
+		m.romBank = 1
+	}
+}
+
+func (m *mbc2) externalRAM() (ram []uint8) {
+	return m.ram[:]
+}
+
+func (m *mbc2) readRAM(address uint16) (value uint8) {
+	if !m.ramEnabled {
+		return 0xFF
+	}
+
+	// only 9 address lines are connected, the upper nibble floats high
+	return m.ram[address&0x01FF] | 0xF0
+}
+
+func (m *mbc2) writeRAM(address uint16, value uint8) (changed bool) {
+	if !m.ramEnabled {
+		return false
+	}
+
+	return storeRAM(m.ram[:], int(address&0x01FF), value&0x0F)
+}
+
+// mbc5 supports up to 8MiB of ROM and 128KiB of RAM, rumble cartridges wire
+// bit 3 of the RAM bank number to a motor, see https://gbdev.io/pandocs/MBC5.html
+type mbc5 struct {
+	rom []uint8
+	ram []uint8
+
+	ramEnabled bool
+	romBank    uint16 // 9 bits, unlike the other controllers bank 0 can be selected
+	ramBank    uint8  // 4 bits, 3 on rumble cartridges
+
+	rumble   func(on bool) // nil unless this is a rumble cartridge
+	rumbling bool
+}
+
+func (m *mbc5) readROM(address uint16) (value uint8) {
+	bank := 0
+	if address >= 0x4000 {
+		bank = int(m.romBank)
+	}
+
+	return m.rom[bankOffset(len(m.rom), romBankSize, bank, address)]
+}
+
+func (m *mbc5) writeROM(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		m.ramEnabled = value == 0x0A
+	case address < 0x3000:
+		m.romBank = m.romBank&0x0100 | uint16(value)
+	case address < 0x4000:
+		m.romBank = uint16(value&0x01)<<8 | m.romBank&0x00FF
+	case address < 0x6000:
+		m.ramBank = value & 0x0F
+
+		if m.rumble != nil {
+			m.ramBank &= 0x07
+
+			on := value&0x08 != 0
+			if on != m.rumbling {
+				m.rumbling = on
+				m.rumble(on)
+			}
+		}
+	}
+}
+
+func (m *mbc5) externalRAM() (ram []uint8) {
+	return m.ram
+}
+
+func (m *mbc5) readRAM(address uint16) (value uint8) {
+	if !m.ramEnabled || len(m.ram) == 0 {
+		return 0xFF
+	}
+
+	return m.ram[bankOffset(len(m.ram), ramBankSize, int(m.ramBank), address)]
+}
+
+func (m *mbc5) writeRAM(address uint16, value uint8) (changed bool) {
+	if !m.ramEnabled || len(m.ram) == 0 {
+		return false
+	}
+
+	return storeRAM(m.ram, bankOffset(len(m.ram), ramBankSize, int(m.ramBank), address), value)
+}
+
+// storeRAM writes a byte of cartridge RAM, reporting whether it was different
+func storeRAM(ram []uint8, offset int, value uint8) (changed bool) {
+	changed = ram[offset] != value
+	ram[offset] = value
+
+	return changed
+}
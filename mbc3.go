@@ -0,0 +1,309 @@
+package goboy
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// rtcTrailerSize is the size of the real-time clock state appended to .sav
+// files by BGB, VBA-M, SameBoy and others
+const rtcTrailerSize = 48
+
+// ErrRTCTrailer is returned when a save's real-time clock state can't be read
+var ErrRTCTrailer = errors.New("invalid RTC trailer")
+
+// mbc3 supports up to 2MiB of ROM, 32KiB of RAM and an optional real-time
+// clock, see https://gbdev.io/pandocs/MBC3.html
+type mbc3 struct {
+	rom []uint8
+	ram []uint8
+	rtc *rtc // nil without a clock
+
+	ramEnabled bool  // also enables the clock registers
+	romBank    uint8 // 7 bits, 0x2000-0x3FFF
+	ramBank    uint8 // RAM bank 0x00-0x07 or clock register 0x08-0x0C, 0x4000-0x5FFF
+	latchReady bool  // a 0x00 was written to 0x6000-0x7FFF, a 0x01 will latch the clock
+}
+
+func (m *mbc3) readROM(address uint16) (value uint8) {
+	bank := 0
+	if address >= 0x4000 {
+		bank = int(m.romBank)
+	}
+
+	return m.rom[bankOffset(len(m.rom), romBankSize, bank, address)]
+}
+
+func (m *mbc3) writeROM(address uint16, value uint8) {
+	switch {
+	case address < 0x2000:
+		m.ramEnabled = value&0x0F == 0x0A
+	case address < 0x4000:
+		m.romBank = value
+		if len(m.rom) <= 0x200000 {
+			// only MBC30 connects the 8th bit
+			m.romBank &= 0x7F
+		}
+
+		if m.romBank == 0 {
+			m.romBank = 1
+		}
+	case address < 0x6000:
+		m.ramBank = value & 0x0F
+	default:
+		if m.rtc != nil && m.latchReady && value == 0x01 {
+			m.rtc.latch()
+		}
+
+		m.latchReady = value == 0x00
+	}
+}
+
+func (m *mbc3) externalRAM() (ram []uint8) {
+	return m.ram
+}
+
+func (m *mbc3) readRAM(address uint16) (value uint8) {
+	if !m.ramEnabled {
+		return 0xFF
+	}
+
+	if m.ramBank >= 0x08 {
+		if m.rtc == nil {
+			return 0xFF
+		}
+
+		return m.rtc.read(m.ramBank)
+	}
+
+	if len(m.ram) == 0 {
+		return 0xFF
+	}
+
+	return m.ram[bankOffset(len(m.ram), ramBankSize, int(m.ramBank), address)]
+}
+
+func (m *mbc3) writeRAM(address uint16, value uint8) (changed bool) {
+	if !m.ramEnabled {
+		return false
+	}
+
+	if m.ramBank >= 0x08 {
+		if m.rtc == nil {
+			return false
+		}
+
+		return m.rtc.write(m.ramBank, value)
+	}
+
+	if len(m.ram) == 0 {
+		return false
+	}
+
+	return storeRAM(m.ram, bankOffset(len(m.ram), ramBankSize, int(m.ramBank), address), value)
+}
+
+// real-time clock registers, selected through the RAM bank register
+const (
+	rtcS  = 0x08 // seconds, 0-59
+	rtcM  = 0x09 // minutes, 0-59
+	rtcH  = 0x0A // hours, 0-23
+	rtcDL = 0x0B // lower 8 bits of the day counter
+	rtcDH = 0x0C // bit 0 is the 9th bit of the day counter, bit 6 halts, bit 7 is the day counter carry
+)
+
+// rtc is the MBC3's real-time clock. Instead of ticking with the emulator it
+// catches up with the wall clock whenever it's accessed, so time keeps passing
+// while the emulator is paused or closed.
+type rtc struct {
+	seconds uint8
+	minutes uint8
+	hours   uint8
+	days    uint16 // 9 bits
+	halt    bool
+	carry   bool // the day counter overflowed, stays set until software clears it
+
+	latched [5]uint8 // S, M, H, DL, DH as of the last latch, this is what software reads
+
+	now     func() time.Time
+	updated time.Time // wall clock time the counters were last brought up to date
+}
+
+// update advances the clock by the whole seconds of wall clock time since the last update
+func (r *rtc) update() {
+	now := r.now()
+
+	if r.halt || now.Before(r.updated) {
+		r.updated = now
+		return
+	}
+
+	elapsed := int64(now.Sub(r.updated) / time.Second)
+	r.updated = r.updated.Add(time.Duration(elapsed) * time.Second)
+
+	r.advance(elapsed)
+}
+
+// advance moves the clock forward by some number of seconds
+func (r *rtc) advance(seconds int64) {
+	// software can write out of range values, they count up to the limit of
+	// their bits and wrap without a carry, so take those one second at a time
+	for seconds > 0 && (r.seconds >= 60 || r.minutes >= 60 || r.hours >= 24) {
+		r.tick()
+		seconds--
+	}
+
+	total := seconds + int64(r.seconds) + int64(r.minutes)*60 + int64(r.hours)*3600
+	days := int64(r.days) + total/86400
+	total %= 86400
+
+	r.hours = uint8(total / 3600)
+	r.minutes = uint8(total / 60 % 60)
+	r.seconds = uint8(total % 60)
+
+	if days > 0x1FF {
+		r.carry = true
+		days &= 0x1FF
+	}
+
+	r.days = uint16(days)
+}
+
+// tick advances the clock by a single second
+func (r *rtc) tick() {
+	if r.seconds != 59 {
+		r.seconds = (r.seconds + 1) & 0x3F
+		return
+	}
+
+	r.seconds = 0
+
+	if r.minutes != 59 {
+		r.minutes = (r.minutes + 1) & 0x3F
+		return
+	}
+
+	r.minutes = 0
+
+	if r.hours != 23 {
+		r.hours = (r.hours + 1) & 0x1F
+		return
+	}
+
+	r.hours = 0
+	r.days++
+
+	if r.days > 0x1FF {
+		r.days = 0
+		r.carry = true
+	}
+}
+
+// registers are the live counters packed like they're read by software
+func (r *rtc) registers() (regs [5]uint8) {
+	dh := uint8(r.days>>8) & 0x01
+	if r.halt {
+		dh |= 0x40
+	}
+
+	if r.carry {
+		dh |= 0x80
+	}
+
+	return [5]uint8{r.seconds, r.minutes, r.hours, uint8(r.days), dh}
+}
+
+// setRegisters loads the live counters from their packed form
+func (r *rtc) setRegisters(regs [5]uint8) {
+	r.seconds = regs[0] & 0x3F
+	r.minutes = regs[1] & 0x3F
+	r.hours = regs[2] & 0x1F
+	r.days = uint16(regs[4]&0x01)<<8 | uint16(regs[3])
+	r.halt = regs[4]&0x40 != 0
+	r.carry = regs[4]&0x80 != 0
+}
+
+func (r *rtc) latch() {
+	r.update()
+	r.latched = r.registers()
+}
+
+func (r *rtc) read(register uint8) (value uint8) {
+	if register > rtcDH {
+		return 0xFF
+	}
+
+	return r.latched[register-rtcS]
+}
+
+func (r *rtc) write(register uint8, value uint8) (changed bool) {
+	if register > rtcDH {
+		return false
+	}
+
+	r.update()
+
+	before := r.registers()
+	regs := before
+	regs[register-rtcS] = value
+	r.setRegisters(regs)
+
+	if register == rtcS {
+		// writing the seconds resets the sub-second counter
+		r.updated = r.now()
+		return true
+	}
+
+	return r.registers() != before
+}
+
+// save writes the clock in the 48 byte trailer format: the live and latched
+// registers as little endian 32 bit values followed by a 64 bit unix timestamp
+func (r *rtc) save(w io.Writer) (err error) {
+	r.update()
+
+	var trailer [rtcTrailerSize]uint8
+
+	regs := r.registers()
+	for i := range regs {
+		binary.LittleEndian.PutUint32(trailer[i*4:], uint32(regs[i]))
+		binary.LittleEndian.PutUint32(trailer[20+i*4:], uint32(r.latched[i]))
+	}
+
+	binary.LittleEndian.PutUint64(trailer[40:], uint64(r.updated.Unix()))
+
+	_, err = w.Write(trailer[:])
+
+	return errors.WithStack(err)
+}
+
+// load restores the clock from a 48 byte trailer, or the older 44 byte variant
+// with a 32 bit timestamp, then catches up on the time passed since it was saved
+func (r *rtc) load(trailer []byte) (err error) {
+	if len(trailer) != rtcTrailerSize && len(trailer) != rtcTrailerSize-4 {
+		return errors.Wrapf(ErrRTCTrailer, "expected %d or %d bytes, got %d", rtcTrailerSize, rtcTrailerSize-4, len(trailer))
+	}
+
+	var regs [5]uint8
+	for i := range regs {
+		regs[i] = uint8(binary.LittleEndian.Uint32(trailer[i*4:]))
+		r.latched[i] = uint8(binary.LittleEndian.Uint32(trailer[20+i*4:]))
+	}
+
+	r.setRegisters(regs)
+
+	var timestamp int64
+	if len(trailer) == rtcTrailerSize {
+		timestamp = int64(binary.LittleEndian.Uint64(trailer[40:]))
+	} else {
+		timestamp = int64(binary.LittleEndian.Uint32(trailer[40:]))
+	}
+
+	r.updated = time.Unix(timestamp, 0)
+	r.update()
+
+	return nil
+}
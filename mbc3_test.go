@@ -0,0 +1,153 @@
+package goboy
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a wall clock that only moves when told to
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() (t time.Time) {
+	return c.t
+}
+
+func newMBC3TestGameBoy(t *testing.T, clock *fakeClock) (gb *GameBoy) {
+	rom := newTestROM(0x10, 0x06, 0x03) // MBC3+TIMER+RAM+BATTERY
+	markBanks(rom)
+	fixChecksums(rom)
+
+	gb = &GameBoy{Now: clock.now}
+	assert.NoError(t, gb.LoadROM(rom))
+
+	gb.WriteMemory(0x0000, 0x0A)
+
+	return gb
+}
+
+func latchRTC(gb *GameBoy) {
+	gb.WriteMemory(0x6000, 0x00)
+	gb.WriteMemory(0x6000, 0x01)
+}
+
+func readRTC(gb *GameBoy, register uint8) (value uint8) {
+	gb.WriteMemory(0x4000, register)
+	return gb.ReadMemory(0xA000)
+}
+
+func TestMBC3Banking(t *testing.T) {
+	gb := newMBC3TestGameBoy(t, &fakeClock{time.Unix(0, 0)})
+
+	gb.WriteMemory(0x2000, 0x7F)
+	assert.Equal(t, uint8(0x7F), gb.ReadMemory(0x5000))
+
+	gb.WriteMemory(0x2000, 0x00)
+	assert.Equal(t, uint8(0x01), gb.ReadMemory(0x5000))
+
+	gb.WriteMemory(0x4000, 0x01)
+	gb.WriteMemory(0xA000, 0x11)
+	gb.WriteMemory(0x4000, 0x02)
+	gb.WriteMemory(0xA000, 0x22)
+	gb.WriteMemory(0x4000, 0x01)
+	assert.Equal(t, uint8(0x11), gb.ReadMemory(0xA000))
+}
+
+func TestMBC3RTCLatch(t *testing.T) {
+	clock := &fakeClock{time.Unix(1_000_000, 0)}
+	gb := newMBC3TestGameBoy(t, clock)
+
+	clock.t = clock.t.Add(1*time.Hour + 2*time.Minute + 3*time.Second)
+
+	// nothing changes until the clock is latched
+	assert.Equal(t, uint8(0x00), readRTC(gb, rtcS))
+
+	latchRTC(gb)
+	assert.Equal(t, uint8(3), readRTC(gb, rtcS))
+	assert.Equal(t, uint8(2), readRTC(gb, rtcM))
+	assert.Equal(t, uint8(1), readRTC(gb, rtcH))
+
+	// the latched value sticks while time moves on
+	clock.t = clock.t.Add(10 * time.Second)
+	assert.Equal(t, uint8(3), readRTC(gb, rtcS))
+
+	// a 0x01 without a 0x00 before it doesn't latch
+	gb.WriteMemory(0x6000, 0x01)
+	assert.Equal(t, uint8(3), readRTC(gb, rtcS))
+
+	latchRTC(gb)
+	assert.Equal(t, uint8(13), readRTC(gb, rtcS))
+}
+
+func TestMBC3RTCHaltAndCarry(t *testing.T) {
+	clock := &fakeClock{time.Unix(0, 0)}
+	gb := newMBC3TestGameBoy(t, clock)
+
+	// halt, then set the clock to the last second of day 511
+	gb.WriteMemory(0x4000, rtcDH)
+	gb.WriteMemory(0xA000, 0x41)
+	gb.WriteMemory(0x4000, rtcDL)
+	gb.WriteMemory(0xA000, 0xFF)
+	gb.WriteMemory(0x4000, rtcH)
+	gb.WriteMemory(0xA000, 23)
+	gb.WriteMemory(0x4000, rtcM)
+	gb.WriteMemory(0xA000, 59)
+	gb.WriteMemory(0x4000, rtcS)
+	gb.WriteMemory(0xA000, 59)
+
+	// halted clocks don't count
+	clock.t = clock.t.Add(time.Minute)
+	latchRTC(gb)
+	assert.Equal(t, uint8(59), readRTC(gb, rtcS))
+
+	// resume
+	gb.WriteMemory(0x4000, rtcDH)
+	gb.WriteMemory(0xA000, 0x01)
+
+	clock.t = clock.t.Add(time.Second)
+	latchRTC(gb)
+	assert.Equal(t, uint8(0), readRTC(gb, rtcS))
+	assert.Equal(t, uint8(0), readRTC(gb, rtcM))
+	assert.Equal(t, uint8(0), readRTC(gb, rtcH))
+	assert.Equal(t, uint8(0), readRTC(gb, rtcDL))
+	assert.Equal(t, uint8(0x80), readRTC(gb, rtcDH))
+}
+
+func TestMBC3RTCOutOfRange(t *testing.T) {
+	clock := &fakeClock{time.Unix(0, 0)}
+	gb := newMBC3TestGameBoy(t, clock)
+
+	// 63 seconds wraps to 0 without carrying into the minutes
+	gb.WriteMemory(0x4000, rtcS)
+	gb.WriteMemory(0xA000, 62)
+
+	clock.t = clock.t.Add(2 * time.Second)
+	latchRTC(gb)
+	assert.Equal(t, uint8(0), readRTC(gb, rtcS))
+	assert.Equal(t, uint8(0), readRTC(gb, rtcM))
+}
+
+func TestRTCTrailer(t *testing.T) {
+	clock := &fakeClock{time.Unix(1_600_000_000, 0)}
+	r := &rtc{now: clock.now, updated: clock.t}
+	r.setRegisters([5]uint8{10, 20, 5, 0x34, 0x01})
+	r.latch()
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, r.save(buf))
+	assert.Equal(t, rtcTrailerSize, buf.Len())
+
+	// restored a day later
+	clock.t = clock.t.Add(24 * time.Hour)
+	restored := &rtc{now: clock.now}
+	assert.NoError(t, restored.load(buf.Bytes()))
+
+	assert.Equal(t, [5]uint8{10, 20, 5, 0x34, 0x01}, restored.latched)
+	assert.Equal(t, [5]uint8{10, 20, 5, 0x35, 0x01}, restored.registers())
+
+	assert.Error(t, restored.load(make([]byte, 12)))
+}
@@ -0,0 +1,195 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// markBanks writes each bank's number at offset 0x1000 into it, clear of the
+// cartridge header in bank 0
+func markBanks(rom []byte) {
+	for bank := 0; bank*romBankSize < len(rom); bank++ {
+		rom[bank*romBankSize+0x1000] = uint8(bank)
+	}
+}
+
+func TestROMWritesDontModifyROM(t *testing.T) {
+	rom := newTestROM(0x00, 0x00, 0x00)
+
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(rom))
+
+	gb.WriteMemory(0x2000, 0x12)
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(0x2000))
+}
+
+func TestUnsupportedCartridge(t *testing.T) {
+	gb := &GameBoy{}
+	err := gb.LoadROM(newTestROM(0xFD, 0x00, 0x00)) // TAMA5
+	assert.True(t, errors.Is(err, ErrUnsupportedCartridge))
+}
+
+func TestMBC1ROMBanking(t *testing.T) {
+	rom := newTestROM(0x01, 0x06, 0x00) // 2MiB
+	markBanks(rom)
+	fixChecksums(rom)
+
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(rom))
+
+	// bank 1 is mapped by default
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(0x1000))
+	assert.Equal(t, uint8(0x01), gb.ReadMemory(0x5000))
+
+	gb.WriteMemory(0x2000, 0x05)
+	assert.Equal(t, uint8(0x05), gb.ReadMemory(0x5000))
+
+	// bank 0 turns into bank 1
+	gb.WriteMemory(0x2000, 0x00)
+	assert.Equal(t, uint8(0x01), gb.ReadMemory(0x5000))
+
+	// the zero check only looks at the lower 5 bits
+	gb.WriteMemory(0x2000, 0x20)
+	assert.Equal(t, uint8(0x01), gb.ReadMemory(0x5000))
+
+	// upper bits come from the secondary register
+	gb.WriteMemory(0x4000, 0x02)
+	gb.WriteMemory(0x2000, 0x03)
+	assert.Equal(t, uint8(0x43), gb.ReadMemory(0x5000))
+
+	// mode 1 applies them to 0x0000-0x3FFF as well
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(0x1000))
+	gb.WriteMemory(0x6000, 0x01)
+	assert.Equal(t, uint8(0x40), gb.ReadMemory(0x1000))
+}
+
+func TestMBC1ROMBankWrapping(t *testing.T) {
+	rom := newTestROM(0x01, 0x02, 0x00) // 128KiB, 8 banks
+	markBanks(rom)
+	fixChecksums(rom)
+
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(rom))
+
+	gb.WriteMemory(0x2000, 0x0A)
+	assert.Equal(t, uint8(0x02), gb.ReadMemory(0x5000))
+}
+
+func TestMBC1RAMBanking(t *testing.T) {
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(newTestROM(0x03, 0x00, 0x03))) // 32KiB RAM
+
+	// disabled RAM reads open bus and ignores writes
+	gb.WriteMemory(0xA000, 0x11)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0xA000))
+
+	gb.WriteMemory(0x0000, 0x0A)
+	gb.WriteMemory(0xA000, 0x11)
+	assert.Equal(t, uint8(0x11), gb.ReadMemory(0xA000))
+
+	// mode 0 always uses RAM bank 0
+	gb.WriteMemory(0x4000, 0x02)
+	assert.Equal(t, uint8(0x11), gb.ReadMemory(0xA000))
+
+	gb.WriteMemory(0x6000, 0x01)
+	gb.WriteMemory(0xA000, 0x22)
+	assert.Equal(t, uint8(0x22), gb.ReadMemory(0xA000))
+
+	gb.WriteMemory(0x4000, 0x00)
+	assert.Equal(t, uint8(0x11), gb.ReadMemory(0xA000))
+
+	gb.WriteMemory(0x0000, 0x00)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0xA000))
+}
+
+func TestMBC1Multicart(t *testing.T) {
+	rom := newTestROM(0x01, 0x05, 0x00) // 1MiB
+	copy(rom[0x10*romBankSize+0x0104:], nintendoLogo[:])
+	markBanks(rom)
+	fixChecksums(rom)
+
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(rom))
+
+	// bank2 is shifted by 4 instead of 5, bit 4 of bank1 is ignored
+	gb.WriteMemory(0x4000, 0x01)
+	gb.WriteMemory(0x2000, 0x12)
+	assert.Equal(t, uint8(0x12), gb.ReadMemory(0x5000))
+
+	gb.WriteMemory(0x6000, 0x01)
+	assert.Equal(t, uint8(0x10), gb.ReadMemory(0x1000))
+}
+
+func TestMBC2(t *testing.T) {
+	rom := newTestROM(0x06, 0x03, 0x00) // MBC2+BATTERY, 256KiB
+	markBanks(rom)
+	fixChecksums(rom)
+
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(rom))
+
+	// address bit 8 set selects the ROM bank
+	gb.WriteMemory(0x2100, 0x0B)
+	assert.Equal(t, uint8(0x0B), gb.ReadMemory(0x5000))
+
+	gb.WriteMemory(0x0100, 0x00)
+	assert.Equal(t, uint8(0x01), gb.ReadMemory(0x5000))
+
+	// address bit 8 clear enables RAM
+	gb.WriteMemory(0x0000, 0x0A)
+	gb.WriteMemory(0xA000, 0x3C)
+	assert.Equal(t, uint8(0xFC), gb.ReadMemory(0xA000))
+
+	// 512 half bytes, echoed through the whole RAM area
+	assert.Equal(t, uint8(0xFC), gb.ReadMemory(0xA200))
+	assert.Equal(t, uint8(0xFC), gb.ReadMemory(0xBE00))
+}
+
+func TestMBC5(t *testing.T) {
+	rom := newTestROM(0x1B, 0x08, 0x04) // 8MiB, 128KiB RAM
+	markBanks(rom)
+	rom[0x100*romBankSize+0x1000] = 0xAB // bank 256's marker doesn't fit in a byte
+	fixChecksums(rom)
+
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(rom))
+
+	gb.WriteMemory(0x2000, 0x00)
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(0x5000))
+
+	gb.WriteMemory(0x2000, 0xFF)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0x5000))
+
+	gb.WriteMemory(0x2000, 0x00)
+	gb.WriteMemory(0x3000, 0x01)
+	assert.Equal(t, uint8(0xAB), gb.ReadMemory(0x5000))
+
+	gb.WriteMemory(0x0000, 0x0A)
+	gb.WriteMemory(0x4000, 0x0F)
+	gb.WriteMemory(0xA000, 0x0F)
+	gb.WriteMemory(0x4000, 0x00)
+	gb.WriteMemory(0xA000, 0x00)
+	gb.WriteMemory(0x4000, 0x0F)
+	assert.Equal(t, uint8(0x0F), gb.ReadMemory(0xA000))
+}
+
+func TestMBC5Rumble(t *testing.T) {
+	var events []bool
+
+	gb := &GameBoy{Rumble: func(on bool) { events = append(events, on) }}
+	assert.NoError(t, gb.LoadROM(newTestROM(0x1D, 0x00, 0x03))) // MBC5+RUMBLE+RAM
+
+	gb.WriteMemory(0x0000, 0x0A)
+	gb.WriteMemory(0x4000, 0x01)
+	gb.WriteMemory(0xA000, 0x11)
+
+	// the motor bit doesn't select a RAM bank
+	gb.WriteMemory(0x4000, 0x09)
+	assert.Equal(t, uint8(0x11), gb.ReadMemory(0xA000))
+	gb.WriteMemory(0x4000, 0x09)
+	gb.WriteMemory(0x4000, 0x01)
+
+	assert.Equal(t, []bool{true, false}, events)
+}
@@ -0,0 +1,320 @@
+package goboy
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultMaxLead = CyclesPerFrame // T-cycles a NetLink may get ahead of the other end, when MaxLead is 0
+
+	linkProtocolVersion = 1
+	linkFrameSize       = 10 // kind, cycles and data
+)
+
+// ErrLinkProtocol is returned by NetLink.Err when the other end isn't a
+// compatible NetLink
+var ErrLinkProtocol = errors.New("link peer speaks a different protocol")
+
+// link protocol frame kinds
+const (
+	frameHello    uint8 = iota // first frame each way, cycles is the protocol version
+	frameSync                  // the sender has run up to cycles, data is 1 when it is stalled waiting for an answer
+	frameTransfer              // the sender clocked out data at cycles, it waits for a frameReply
+	frameReply                 // answer to a frameTransfer, data is the byte clocked back
+)
+
+// linkFrame is the unit of the link protocol. On the wire it is the kind, the
+// cycles as a big endian uint64 and the data, 10 bytes in all.
+type linkFrame struct {
+	kind   uint8
+	cycles uint64 // T-cycles since the sender's first Sync
+	data   uint8
+}
+
+// LinkClock is implemented by link cables that keep both ends in step. The
+// serial port calls Sync with the T-cycle count on every edge of its clock,
+// whether or not a transfer is under way.
+type LinkClock interface {
+	Sync(cycles uint64)
+}
+
+// NetLink is a LinkCable to a GameBoy in another process, over any stream
+// connection such as TCP or a Unix socket. Besides the bytes, both ends
+// exchange how far they have run, and an end that gets more than MaxLead
+// T-cycles ahead waits for the other to catch up. An end also waits while
+// the byte it clocked out makes the round trip. Once the connection ends the
+// link behaves like nothing is plugged in.
+type NetLink struct {
+	MaxLead uint64 // DefaultMaxLead when 0
+
+	conn   net.Conn
+	frames chan linkFrame // from the reader goroutine, closed when the connection ends
+	err    error          // why the connection ended, set before frames is closed
+	closed bool           // frames was closed
+
+	started bool   // Sync was called, last is set
+	last    uint64 // cycles passed to the last Sync
+	local   uint64 // cycles this end has run, the protocol counts from the first Sync
+	remote  uint64 // cycles the other end has run, as far as this end knows
+	sent    uint64 // cycles last sent to the other end
+	asked   bool   // the other end is stalled, it wants to hear how far this end has run
+
+	pending     []linkFrame // transfers from the other end this end hasn't run up to yet
+	readyCalled bool        // Ready was called since the last Sync
+	waiting     bool        // Ready was called on the last edge, this end waits on the external clock
+	offered     uint8       // the byte this end will clock out when it is waiting
+	delivered   bool        // a byte was clocked in from the other end
+	received    uint8       // the byte clocked in
+}
+
+// DialLink connects to a GameBoy listening with AcceptLink
+func DialLink(network, address string) (link *NetLink, err error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to dial link")
+	}
+
+	return NewNetLink(conn), nil
+}
+
+// AcceptLink waits for a GameBoy to connect with DialLink
+func AcceptLink(listener net.Listener) (link *NetLink, err error) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to accept link")
+	}
+
+	return NewNetLink(conn), nil
+}
+
+// NewNetLink speaks the link protocol over an established connection, the
+// NetLink owns it from then on
+func NewNetLink(conn net.Conn) (link *NetLink) {
+	link = &NetLink{
+		conn:   conn,
+		frames: make(chan linkFrame, 64),
+	}
+
+	go link.read()
+	link.send(frameHello, linkProtocolVersion, 0)
+
+	return link
+}
+
+// Close disconnects the link
+func (n *NetLink) Close() (err error) {
+	return n.conn.Close()
+}
+
+// Err is why the connection ended, once it has. It belongs to the GameBoy's
+// goroutine like the rest of the link.
+func (n *NetLink) Err() (err error) {
+	if !n.closed {
+		return nil
+	}
+
+	return n.err
+}
+
+// read decodes frames until the connection ends, checking the hello first
+func (n *NetLink) read() {
+	defer close(n.frames)
+
+	var buf [linkFrameSize]byte
+	hello := true
+
+	for {
+		if _, err := io.ReadFull(n.conn, buf[:]); err != nil {
+			n.err = errors.Wrap(err, "link disconnected")
+			return
+		}
+
+		f := linkFrame{
+			kind:   buf[0],
+			cycles: binary.BigEndian.Uint64(buf[1:9]),
+			data:   buf[9],
+		}
+
+		if hello {
+			if f.kind != frameHello || f.cycles != linkProtocolVersion {
+				n.err = ErrLinkProtocol
+				n.conn.Close()
+				return
+			}
+
+			hello = false
+			continue
+		}
+
+		n.frames <- f
+	}
+}
+
+func (n *NetLink) send(kind uint8, cycles uint64, data uint8) {
+	var buf [linkFrameSize]byte
+	buf[0] = kind
+	binary.BigEndian.PutUint64(buf[1:9], cycles)
+	buf[9] = data
+
+	if _, err := n.conn.Write(buf[:]); err != nil {
+		// the reader notices too and ends the link
+		n.conn.Close()
+	}
+
+	if kind != frameHello {
+		n.sent = cycles
+	}
+}
+
+// receive takes the next frame from the other end, waiting for one if block
+// is set. It returns false when there is none.
+func (n *NetLink) receive(block bool) (f linkFrame, ok bool) {
+	if n.closed {
+		return f, false
+	}
+
+	if block {
+		f, ok = <-n.frames
+	} else {
+		select {
+		case f, ok = <-n.frames:
+		default:
+			return f, false
+		}
+	}
+
+	if !ok {
+		n.closed = true
+		return f, false
+	}
+
+	if f.cycles > n.remote {
+		n.remote = f.cycles
+	}
+
+	switch f.kind {
+	case frameSync:
+		n.asked = f.data == 1
+	case frameTransfer:
+		n.pending = append(n.pending, f)
+	}
+
+	return f, true
+}
+
+// answer replies to the transfers this end has run up to, the first one
+// takes the offered byte if this end was waiting and the rest get a high line
+func (n *NetLink) answer(all bool) {
+	kept := n.pending[:0]
+
+	for _, f := range n.pending {
+		if f.cycles > n.local && !all {
+			kept = append(kept, f)
+			continue
+		}
+
+		reply := uint8(0xFF)
+		if n.waiting && !n.delivered {
+			reply = n.offered
+			n.waiting = false
+			n.delivered, n.received = true, f.data
+		}
+
+		n.send(frameReply, n.local, reply)
+	}
+
+	n.pending = kept
+}
+
+func (n *NetLink) maxLead() (lead uint64) {
+	if n.MaxLead > 0 {
+		return n.MaxLead
+	}
+
+	return DefaultMaxLead
+}
+
+// Sync tells the other end how far this end has run, now and then, and
+// waits while this end is too far ahead
+func (n *NetLink) Sync(cycles uint64) {
+	if !n.started || cycles < n.last {
+		// the first Sync, or the GameBoy was reset and its count starts over
+		n.started, n.last = true, cycles
+	}
+
+	n.local += cycles - n.last
+	n.last = cycles
+	n.waiting = n.readyCalled
+	n.readyCalled = false
+
+	for _, ok := n.receive(false); ok; _, ok = n.receive(false) {
+	}
+
+	n.answer(false)
+
+	if n.asked || n.local-n.sent >= n.maxLead()/4 {
+		n.send(frameSync, n.local, 0)
+	}
+
+	if n.closed || n.local <= n.remote+n.maxLead() {
+		return
+	}
+
+	// the other end keeps sending its progress until this end is unstuck
+	n.send(frameSync, n.local, 1)
+
+	for !n.closed && n.local > n.remote+n.maxLead() {
+		n.receive(true)
+		n.answer(false)
+	}
+
+	n.send(frameSync, n.local, 0)
+}
+
+// Transfer sends a byte to the other end and waits for the one it clocks back
+func (n *NetLink) Transfer(out uint8) (in uint8) {
+	if n.closed {
+		return 0xFF
+	}
+
+	n.send(frameTransfer, n.local, out)
+
+	for {
+		f, ok := n.receive(true)
+		if !ok {
+			return 0xFF
+		}
+
+		if f.kind == frameReply {
+			return f.data
+		}
+
+		// this end isn't waiting on the other's clock, and the other end may
+		// be stuck in a transfer of its own
+		n.waiting = false
+		n.answer(true)
+	}
+}
+
+// Ready offers a byte to the other end, returning what it clocked in once it has
+func (n *NetLink) Ready(out uint8) (in uint8, ok bool) {
+	n.readyCalled = true
+	n.offered = out
+
+	if n.delivered {
+		n.delivered = false
+		return n.received, true
+	}
+
+	return 0, false
+}
+
+// Cancel withdraws the offered byte, along with any the other end clocked in
+// that this end hasn't picked up
+func (n *NetLink) Cancel() {
+	n.readyCalled, n.waiting, n.delivered = false, false, false
+}
@@ -0,0 +1,179 @@
+package goboy
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNetLinks connects two NetLinks over localhost TCP
+func newTestNetLinks(t *testing.T) (a, b *NetLink) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	accepted := make(chan *NetLink)
+	go func() {
+		link, err := AcceptLink(listener)
+		assert.NoError(t, err)
+		accepted <- link
+	}()
+
+	a, err = DialLink("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	b = <-accepted
+	require.NotNil(t, b)
+
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+
+	return a, b
+}
+
+func TestNetLinkTransfer(t *testing.T) {
+	a, b := &GameBoy{}, &GameBoy{}
+	linkA, linkB := newTestNetLinks(t)
+	a.Link, b.Link = linkA, linkB
+
+	// close enough in step that neither finishes before the transfer
+	linkA.MaxLead, linkB.MaxLead = 1024, 1024
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		defer linkA.Close()
+
+		ticks(a, serialBitTicks*10)
+		a.WriteMemory(SB, 0xAA)
+		a.WriteMemory(SC, 0x81)
+		ticks(a, serialBitTicks*20)
+	}()
+
+	go func() {
+		defer wg.Done()
+		defer linkB.Close()
+
+		b.WriteMemory(SB, 0x55)
+		b.WriteMemory(SC, 0x80)
+		ticks(b, serialBitTicks*40)
+	}()
+
+	wg.Wait()
+
+	assert.Equal(t, uint8(0x55), a.ReadMemory(SB))
+	assert.Equal(t, uint8(0xAA), b.ReadMemory(SB))
+	assert.Equal(t, uint8(0x7F), a.ReadMemory(SC))
+	assert.Equal(t, uint8(0x7E), b.ReadMemory(SC))
+	assert.NotZero(t, a.ReadMemory(IF)&interruptSerial)
+	assert.NotZero(t, b.ReadMemory(IF)&interruptSerial)
+}
+
+func TestNetLinkStall(t *testing.T) {
+	a, b := newTestNetLinks(t)
+	a.MaxLead = 1000
+
+	a.Sync(0)
+	b.Sync(0)
+
+	done := make(chan struct{})
+	go func() {
+		a.Sync(5000)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("ran too far ahead")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// not far enough for a
+	b.Sync(3000)
+
+	select {
+	case <-done:
+		t.Fatal("ran too far ahead")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	b.Sync(4000)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("still stalled")
+	}
+}
+
+func TestNetLinkReset(t *testing.T) {
+	a, b := newTestNetLinks(t)
+	a.MaxLead = 1000
+
+	a.Sync(100000)
+	b.Sync(100000)
+
+	// a reset starts a's count over, the link carries on from where it was
+	done := make(chan struct{})
+	go func() {
+		a.Sync(512)
+		a.Sync(1024)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stalled after the reset")
+	}
+
+	assert.Equal(t, uint64(512), a.local)
+}
+
+func TestNetLinkDisconnect(t *testing.T) {
+	a, b := newTestNetLinks(t)
+	a.MaxLead = 1000
+
+	a.Sync(0)
+	b.Close()
+
+	// no waiting on an end that is gone
+	a.Sync(1 << 20)
+	assert.Equal(t, uint8(0xFF), a.Transfer(0x12))
+	assert.Error(t, a.Err())
+}
+
+func TestNetLinkProtocol(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// some other protocol entirely
+		conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n"))
+		<-done
+	}()
+
+	link, err := DialLink("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer link.Close()
+
+	assert.Equal(t, uint8(0xFF), link.Transfer(0x12))
+	assert.Equal(t, ErrLinkProtocol, link.Err())
+}
@@ -100,6 +100,8 @@ type OpBytes struct {
 	Code            OpCode
 	HasDisplacement bool
 	ImmediateSize   uint8 // 0, 1, 2
+	Cycles          uint8 // M-cycles (4 clock ticks each), including fetching the opcode and any prefix
+	BranchCycles    uint8 // M-cycles when the instruction jumps, 0 for instructions that never do
 	Operation       func(gameboy *GameBoy, prefix uint8, opcode OpCode, displacement uint8, immediate uint16)
 }
 
@@ -129,546 +131,546 @@ func (code OpCode) GetPQ() (p uint8, q uint8) {
 var unprefixed = map[byte]OpBytes{
 	//XX_YYY_ZZZ
 	//   PPQ
-	0b00_000_000: {NOP, false, 0, nil},
-	0b00_001_000: {LD, false, 2, nil},
-	0b00_010_000: {STOP, false, 0, nil},
-	0b00_011_000: {JR, true, 0, jr}, // JR d
-	0b00_100_000: {JR, true, 0, jr}, // JR NZ, d
-	0b00_101_000: {JR, true, 0, jr}, // JR Z, d
-	0b00_110_000: {JR, true, 0, jr}, // JR NC, d
-	0b00_111_000: {JR, true, 0, jr}, // JR C, d
+	0b00_000_000: {NOP, false, 0, 1, 0, nop},   // NOP
+	0b00_001_000: {LD, false, 2, 5, 0, ld},     // LD (nn), SP
+	0b00_010_000: {STOP, false, 1, 1, 0, stop}, // STOP, followed by an ignored byte
+	0b00_011_000: {JR, true, 0, 3, 3, jr},      // JR d
+	0b00_100_000: {JR, true, 0, 2, 3, jr},      // JR NZ, d
+	0b00_101_000: {JR, true, 0, 2, 3, jr},      // JR Z, d
+	0b00_110_000: {JR, true, 0, 2, 3, jr},      // JR NC, d
+	0b00_111_000: {JR, true, 0, 2, 3, jr},      // JR C, d
 
-	0b00_000_001: {LD, false, 2, nil},
-	0b00_010_001: {LD, false, 2, ld}, // LD DE, nn
-	0b00_100_001: {LD, false, 2, ld}, // LD HL, nn
-	0b00_110_001: {LD, false, 2, ld}, // LD SP, nn
+	0b00_000_001: {LD, false, 2, 3, 0, ld}, // LD BC, nn
+	0b00_010_001: {LD, false, 2, 3, 0, ld}, // LD DE, nn
+	0b00_100_001: {LD, false, 2, 3, 0, ld}, // LD HL, nn
+	0b00_110_001: {LD, false, 2, 3, 0, ld}, // LD SP, nn
 
-	0b00_001_001: {ADD, false, 0, nil},
-	0b00_011_001: {ADD, false, 0, nil},
-	0b00_101_001: {ADD, false, 0, nil},
-	0b00_111_001: {ADD, false, 0, nil},
+	0b00_001_001: {ADD, false, 0, 2, 0, add}, // ADD HL, BC
+	0b00_011_001: {ADD, false, 0, 2, 0, add}, // ADD HL, DE
+	0b00_101_001: {ADD, false, 0, 2, 0, add}, // ADD HL, HL
+	0b00_111_001: {ADD, false, 0, 2, 0, add}, // ADD HL, SP
 
-	0b00_000_010: {LD, false, 0, ldid},  // LD (BC), A
-	0b00_010_010: {LD, false, 0, ldid},  // LD (DE), A
-	0b00_100_010: {LDI, false, 0, ldid}, // LD (HL+), A which is equivalent to LDI (HL), A
-	0b00_110_010: {LDD, false, 0, ldid}, // LD (HL-), A which is equivalent to LDD (HL), A
+	0b00_000_010: {LD, false, 0, 2, 0, ldid},  // LD (BC), A
+	0b00_010_010: {LD, false, 0, 2, 0, ldid},  // LD (DE), A
+	0b00_100_010: {LDI, false, 0, 2, 0, ldid}, // LD (HL+), A which is equivalent to LDI (HL), A
+	0b00_110_010: {LDD, false, 0, 2, 0, ldid}, // LD (HL-), A which is equivalent to LDD (HL), A
 
-	0b00_001_010: {LD, false, 0, ldid},  // LD A, (BC)
-	0b00_011_010: {LD, false, 0, ldid},  // LD A, (DE)
-	0b00_101_010: {LDI, false, 0, ldid}, // LD A, (HL+)
-	0b00_111_010: {LDD, false, 0, ldid}, // LD A, (HL-)
+	0b00_001_010: {LD, false, 0, 2, 0, ldid},  // LD A, (BC)
+	0b00_011_010: {LD, false, 0, 2, 0, ldid},  // LD A, (DE)
+	0b00_101_010: {LDI, false, 0, 2, 0, ldid}, // LD A, (HL+)
+	0b00_111_010: {LDD, false, 0, 2, 0, ldid}, // LD A, (HL-)
 
-	0b00_000_011: {INC, false, 0, nil},
-	0b00_010_011: {INC, false, 0, nil},
-	0b00_100_011: {INC, false, 0, nil},
-	0b00_110_011: {INC, false, 0, nil},
+	0b00_000_011: {INC, false, 0, 2, 0, inc}, // INC BC
+	0b00_010_011: {INC, false, 0, 2, 0, inc}, // INC DE
+	0b00_100_011: {INC, false, 0, 2, 0, inc}, // INC HL
+	0b00_110_011: {INC, false, 0, 2, 0, inc}, // INC SP
 
-	0b00_001_011: {DEC, false, 0, nil},
-	0b00_011_011: {DEC, false, 0, nil},
-	0b00_101_011: {DEC, false, 0, nil},
-	0b00_111_011: {DEC, false, 0, nil},
+	0b00_001_011: {DEC, false, 0, 2, 0, dec}, // DEC BC
+	0b00_011_011: {DEC, false, 0, 2, 0, dec}, // DEC DE
+	0b00_101_011: {DEC, false, 0, 2, 0, dec}, // DEC HL
+	0b00_111_011: {DEC, false, 0, 2, 0, dec}, // DEC SP
 
-	0b00_000_100: {INC, false, 0, inc}, // INC B
-	0b00_001_100: {INC, false, 0, inc}, // INC C
-	0b00_010_100: {INC, false, 0, inc}, // INC D
-	0b00_011_100: {INC, false, 0, inc}, // INC E
-	0b00_100_100: {INC, false, 0, inc}, // INC H
-	0b00_101_100: {INC, false, 0, inc}, // INC L
-	0b00_110_100: {INC, false, 0, inc}, // INC (HL)
-	0b00_111_100: {INC, false, 0, inc}, // INC A
+	0b00_000_100: {INC, false, 0, 1, 0, inc}, // INC B
+	0b00_001_100: {INC, false, 0, 1, 0, inc}, // INC C
+	0b00_010_100: {INC, false, 0, 1, 0, inc}, // INC D
+	0b00_011_100: {INC, false, 0, 1, 0, inc}, // INC E
+	0b00_100_100: {INC, false, 0, 1, 0, inc}, // INC H
+	0b00_101_100: {INC, false, 0, 1, 0, inc}, // INC L
+	0b00_110_100: {INC, false, 0, 3, 0, inc}, // INC (HL)
+	0b00_111_100: {INC, false, 0, 1, 0, inc}, // INC A
 	//XX_YYY_ZZZ
 	//   PPQ
-	0b00_000_101: {DEC, false, 0, dec}, // DEC B
-	0b00_001_101: {DEC, false, 0, dec}, // DEC C
-	0b00_010_101: {DEC, false, 0, dec}, // DEC D
-	0b00_011_101: {DEC, false, 0, dec}, // DEC E
-	0b00_100_101: {DEC, false, 0, dec}, // DEC H
-	0b00_101_101: {DEC, false, 0, dec}, // DEC L
-	0b00_110_101: {DEC, false, 0, dec}, // DEC (HL)
-	0b00_111_101: {DEC, false, 0, dec}, // DEC A
+	0b00_000_101: {DEC, false, 0, 1, 0, dec}, // DEC B
+	0b00_001_101: {DEC, false, 0, 1, 0, dec}, // DEC C
+	0b00_010_101: {DEC, false, 0, 1, 0, dec}, // DEC D
+	0b00_011_101: {DEC, false, 0, 1, 0, dec}, // DEC E
+	0b00_100_101: {DEC, false, 0, 1, 0, dec}, // DEC H
+	0b00_101_101: {DEC, false, 0, 1, 0, dec}, // DEC L
+	0b00_110_101: {DEC, false, 0, 3, 0, dec}, // DEC (HL)
+	0b00_111_101: {DEC, false, 0, 1, 0, dec}, // DEC A
 
-	0b00_000_110: {LD, false, 1, ld}, // LD B, n
-	0b00_001_110: {LD, false, 1, ld}, // LD C, n
-	0b00_010_110: {LD, false, 1, ld}, // LD D, n
-	0b00_011_110: {LD, false, 1, ld}, // LD E, n
-	0b00_100_110: {LD, false, 1, ld}, // LD H, n
-	0b00_101_110: {LD, false, 1, ld}, // LD L, n
-	0b00_110_110: {LD, false, 1, ld}, // LD (HL), n
-	0b00_111_110: {LD, false, 1, ld}, // LD A, n
+	0b00_000_110: {LD, false, 1, 2, 0, ld}, // LD B, n
+	0b00_001_110: {LD, false, 1, 2, 0, ld}, // LD C, n
+	0b00_010_110: {LD, false, 1, 2, 0, ld}, // LD D, n
+	0b00_011_110: {LD, false, 1, 2, 0, ld}, // LD E, n
+	0b00_100_110: {LD, false, 1, 2, 0, ld}, // LD H, n
+	0b00_101_110: {LD, false, 1, 2, 0, ld}, // LD L, n
+	0b00_110_110: {LD, false, 1, 3, 0, ld}, // LD (HL), n
+	0b00_111_110: {LD, false, 1, 2, 0, ld}, // LD A, n
 
-	0b00_000_111: {RLCA, false, 0, nil},
-	0b00_001_111: {RRCA, false, 0, nil},
-	0b00_010_111: {RLA, false, 0, rla}, // RLA
-	0b00_011_111: {RRA, false, 0, nil},
-	0b00_100_111: {DAA, false, 0, nil},
-	0b00_101_111: {CPL, false, 0, nil},
-	0b00_110_111: {SCF, false, 0, nil},
-	0b00_111_111: {CCF, false, 0, nil},
+	0b00_000_111: {RLCA, false, 0, 1, 0, rota}, // RLCA
+	0b00_001_111: {RRCA, false, 0, 1, 0, rota}, // RRCA
+	0b00_010_111: {RLA, false, 0, 1, 0, rota},  // RLA
+	0b00_011_111: {RRA, false, 0, 1, 0, rota},  // RRA
+	0b00_100_111: {DAA, false, 0, 1, 0, daa},   // DAA
+	0b00_101_111: {CPL, false, 0, 1, 0, cpl},   // CPL
+	0b00_110_111: {SCF, false, 0, 1, 0, scf},   // SCF
+	0b00_111_111: {CCF, false, 0, 1, 0, ccf},   // CCF
 
-	0b01_000_000: {LD, false, 0, nil},
-	0b01_000_001: {LD, false, 0, nil},
-	0b01_000_010: {LD, false, 0, nil},
-	0b01_000_011: {LD, false, 0, nil},
-	0b01_000_100: {LD, false, 0, nil},
-	0b01_000_101: {LD, false, 0, nil},
-	0b01_000_110: {LD, false, 0, nil},
-	0b01_000_111: {LD, false, 0, nil},
-	0b01_001_000: {LD, false, 0, nil},
-	0b01_001_001: {LD, false, 0, nil},
-	0b01_001_010: {LD, false, 0, nil},
-	0b01_001_011: {LD, false, 0, nil},
-	0b01_001_100: {LD, false, 0, nil},
-	0b01_001_101: {LD, false, 0, nil},
-	0b01_001_110: {LD, false, 0, nil},
-	0b01_001_111: {LD, false, 0, ld}, // LD C, A
-	0b01_010_000: {LD, false, 0, nil},
-	0b01_010_001: {LD, false, 0, nil},
-	0b01_010_010: {LD, false, 0, nil},
-	0b01_010_011: {LD, false, 0, nil},
-	0b01_010_100: {LD, false, 0, nil},
-	0b01_010_101: {LD, false, 0, nil},
-	0b01_010_110: {LD, false, 0, nil},
-	0b01_010_111: {LD, false, 0, nil},
-	0b01_011_000: {LD, false, 0, nil},
-	0b01_011_001: {LD, false, 0, nil},
-	0b01_011_010: {LD, false, 0, nil},
-	0b01_011_011: {LD, false, 0, nil},
-	0b01_011_100: {LD, false, 0, nil},
-	0b01_011_101: {LD, false, 0, nil},
-	0b01_011_110: {LD, false, 0, nil},
-	0b01_011_111: {LD, false, 0, nil},
-	0b01_100_000: {LD, false, 0, nil},
-	0b01_100_001: {LD, false, 0, nil},
-	0b01_100_010: {LD, false, 0, nil},
-	0b01_100_011: {LD, false, 0, nil},
-	0b01_100_100: {LD, false, 0, nil},
-	0b01_100_101: {LD, false, 0, nil},
-	0b01_100_110: {LD, false, 0, nil},
-	0b01_100_111: {LD, false, 0, nil},
-	0b01_101_000: {LD, false, 0, nil},
-	0b01_101_001: {LD, false, 0, nil},
-	0b01_101_010: {LD, false, 0, nil},
-	0b01_101_011: {LD, false, 0, nil},
-	0b01_101_100: {LD, false, 0, nil},
-	0b01_101_101: {LD, false, 0, nil},
-	0b01_101_110: {LD, false, 0, nil},
-	0b01_101_111: {LD, false, 0, nil},
-	0b01_110_000: {LD, false, 0, nil},
-	0b01_110_001: {LD, false, 0, nil},
-	0b01_110_010: {LD, false, 0, nil},
-	0b01_110_011: {LD, false, 0, nil},
-	0b01_110_100: {LD, false, 0, nil},
-	0b01_110_101: {LD, false, 0, nil},
-	0b01_110_110: {HALT, false, 0, nil},
-	0b01_110_111: {LD, false, 0, ld}, // LD (HL), A
-	0b01_111_000: {LD, false, 0, nil},
-	0b01_111_001: {LD, false, 0, nil},
-	0b01_111_010: {LD, false, 0, nil},
-	0b01_111_011: {LD, false, 0, nil},
-	0b01_111_100: {LD, false, 0, nil},
-	0b01_111_101: {LD, false, 0, nil},
-	0b01_111_110: {LD, false, 0, nil},
-	0b01_111_111: {LD, false, 0, nil},
+	0b01_000_000: {LD, false, 0, 1, 0, ld},     // LD B, B
+	0b01_000_001: {LD, false, 0, 1, 0, ld},     // LD B, C
+	0b01_000_010: {LD, false, 0, 1, 0, ld},     // LD B, D
+	0b01_000_011: {LD, false, 0, 1, 0, ld},     // LD B, E
+	0b01_000_100: {LD, false, 0, 1, 0, ld},     // LD B, H
+	0b01_000_101: {LD, false, 0, 1, 0, ld},     // LD B, L
+	0b01_000_110: {LD, false, 0, 2, 0, ld},     // LD B, (HL)
+	0b01_000_111: {LD, false, 0, 1, 0, ld},     // LD B, A
+	0b01_001_000: {LD, false, 0, 1, 0, ld},     // LD C, B
+	0b01_001_001: {LD, false, 0, 1, 0, ld},     // LD C, C
+	0b01_001_010: {LD, false, 0, 1, 0, ld},     // LD C, D
+	0b01_001_011: {LD, false, 0, 1, 0, ld},     // LD C, E
+	0b01_001_100: {LD, false, 0, 1, 0, ld},     // LD C, H
+	0b01_001_101: {LD, false, 0, 1, 0, ld},     // LD C, L
+	0b01_001_110: {LD, false, 0, 2, 0, ld},     // LD C, (HL)
+	0b01_001_111: {LD, false, 0, 1, 0, ld},     // LD C, A
+	0b01_010_000: {LD, false, 0, 1, 0, ld},     // LD D, B
+	0b01_010_001: {LD, false, 0, 1, 0, ld},     // LD D, C
+	0b01_010_010: {LD, false, 0, 1, 0, ld},     // LD D, D
+	0b01_010_011: {LD, false, 0, 1, 0, ld},     // LD D, E
+	0b01_010_100: {LD, false, 0, 1, 0, ld},     // LD D, H
+	0b01_010_101: {LD, false, 0, 1, 0, ld},     // LD D, L
+	0b01_010_110: {LD, false, 0, 2, 0, ld},     // LD D, (HL)
+	0b01_010_111: {LD, false, 0, 1, 0, ld},     // LD D, A
+	0b01_011_000: {LD, false, 0, 1, 0, ld},     // LD E, B
+	0b01_011_001: {LD, false, 0, 1, 0, ld},     // LD E, C
+	0b01_011_010: {LD, false, 0, 1, 0, ld},     // LD E, D
+	0b01_011_011: {LD, false, 0, 1, 0, ld},     // LD E, E
+	0b01_011_100: {LD, false, 0, 1, 0, ld},     // LD E, H
+	0b01_011_101: {LD, false, 0, 1, 0, ld},     // LD E, L
+	0b01_011_110: {LD, false, 0, 2, 0, ld},     // LD E, (HL)
+	0b01_011_111: {LD, false, 0, 1, 0, ld},     // LD E, A
+	0b01_100_000: {LD, false, 0, 1, 0, ld},     // LD H, B
+	0b01_100_001: {LD, false, 0, 1, 0, ld},     // LD H, C
+	0b01_100_010: {LD, false, 0, 1, 0, ld},     // LD H, D
+	0b01_100_011: {LD, false, 0, 1, 0, ld},     // LD H, E
+	0b01_100_100: {LD, false, 0, 1, 0, ld},     // LD H, H
+	0b01_100_101: {LD, false, 0, 1, 0, ld},     // LD H, L
+	0b01_100_110: {LD, false, 0, 2, 0, ld},     // LD H, (HL)
+	0b01_100_111: {LD, false, 0, 1, 0, ld},     // LD H, A
+	0b01_101_000: {LD, false, 0, 1, 0, ld},     // LD L, B
+	0b01_101_001: {LD, false, 0, 1, 0, ld},     // LD L, C
+	0b01_101_010: {LD, false, 0, 1, 0, ld},     // LD L, D
+	0b01_101_011: {LD, false, 0, 1, 0, ld},     // LD L, E
+	0b01_101_100: {LD, false, 0, 1, 0, ld},     // LD L, H
+	0b01_101_101: {LD, false, 0, 1, 0, ld},     // LD L, L
+	0b01_101_110: {LD, false, 0, 2, 0, ld},     // LD L, (HL)
+	0b01_101_111: {LD, false, 0, 1, 0, ld},     // LD L, A
+	0b01_110_000: {LD, false, 0, 2, 0, ld},     // LD (HL), B
+	0b01_110_001: {LD, false, 0, 2, 0, ld},     // LD (HL), C
+	0b01_110_010: {LD, false, 0, 2, 0, ld},     // LD (HL), D
+	0b01_110_011: {LD, false, 0, 2, 0, ld},     // LD (HL), E
+	0b01_110_100: {LD, false, 0, 2, 0, ld},     // LD (HL), H
+	0b01_110_101: {LD, false, 0, 2, 0, ld},     // LD (HL), L
+	0b01_110_110: {HALT, false, 0, 1, 0, halt}, // HALT
+	0b01_110_111: {LD, false, 0, 2, 0, ld},     // LD (HL), A
+	0b01_111_000: {LD, false, 0, 1, 0, ld},     // LD A, B
+	0b01_111_001: {LD, false, 0, 1, 0, ld},     // LD A, C
+	0b01_111_010: {LD, false, 0, 1, 0, ld},     // LD A, D
+	0b01_111_011: {LD, false, 0, 1, 0, ld},     // LD A, E
+	0b01_111_100: {LD, false, 0, 1, 0, ld},     // LD A, H
+	0b01_111_101: {LD, false, 0, 1, 0, ld},     // LD A, L
+	0b01_111_110: {LD, false, 0, 2, 0, ld},     // LD A, (HL)
+	0b01_111_111: {LD, false, 0, 1, 0, ld},     // LD A, A
 	//XX_YYY_ZZZ
 	//   PPQ
-	0b10_000_000: {ALU, false, 0, nil},
-	0b10_000_001: {ALU, false, 0, nil},
-	0b10_000_010: {ALU, false, 0, nil},
-	0b10_000_011: {ALU, false, 0, nil},
-	0b10_000_100: {ALU, false, 0, nil},
-	0b10_000_101: {ALU, false, 0, nil},
-	0b10_000_110: {ALU, false, 0, nil},
-	0b10_000_111: {ALU, false, 0, nil},
-	0b10_001_000: {ALU, false, 0, nil},
-	0b10_001_001: {ALU, false, 0, nil},
-	0b10_001_010: {ALU, false, 0, nil},
-	0b10_001_011: {ALU, false, 0, nil},
-	0b10_001_100: {ALU, false, 0, nil},
-	0b10_001_101: {ALU, false, 0, nil},
-	0b10_001_110: {ALU, false, 0, nil},
-	0b10_001_111: {ALU, false, 0, nil},
-	0b10_010_000: {ALU, false, 0, nil},
-	0b10_010_001: {ALU, false, 0, nil},
-	0b10_010_010: {ALU, false, 0, nil},
-	0b10_010_011: {ALU, false, 0, nil},
-	0b10_010_100: {ALU, false, 0, nil},
-	0b10_010_101: {ALU, false, 0, nil},
-	0b10_010_110: {ALU, false, 0, nil},
-	0b10_010_111: {ALU, false, 0, nil},
-	0b10_011_000: {ALU, false, 0, nil},
-	0b10_011_001: {ALU, false, 0, nil},
-	0b10_011_010: {ALU, false, 0, nil},
-	0b10_011_011: {ALU, false, 0, nil},
-	0b10_011_100: {ALU, false, 0, nil},
-	0b10_011_101: {ALU, false, 0, nil},
-	0b10_011_110: {ALU, false, 0, nil},
-	0b10_011_111: {ALU, false, 0, nil},
-	0b10_100_000: {ALU, false, 0, nil},
-	0b10_100_001: {ALU, false, 0, nil},
-	0b10_100_010: {ALU, false, 0, nil},
-	0b10_100_011: {ALU, false, 0, nil},
-	0b10_100_100: {ALU, false, 0, nil},
-	0b10_100_101: {ALU, false, 0, nil},
-	0b10_100_110: {ALU, false, 0, nil},
-	0b10_100_111: {ALU, false, 0, nil},
-	0b10_101_000: {ALU, false, 0, nil},
-	0b10_101_001: {ALU, false, 0, nil},
-	0b10_101_010: {ALU, false, 0, nil},
-	0b10_101_011: {ALU, false, 0, nil},
-	0b10_101_100: {ALU, false, 0, nil},
-	0b10_101_101: {ALU, false, 0, nil},
-	0b10_101_110: {ALU, false, 0, nil},
-	0b10_101_111: {ALU, false, 0, xor}, // XOR A
-	0b10_110_000: {ALU, false, 0, nil},
-	0b10_110_001: {ALU, false, 0, nil},
-	0b10_110_010: {ALU, false, 0, nil},
-	0b10_110_011: {ALU, false, 0, nil},
-	0b10_110_100: {ALU, false, 0, nil},
-	0b10_110_101: {ALU, false, 0, nil},
-	0b10_110_110: {ALU, false, 0, nil},
-	0b10_110_111: {ALU, false, 0, nil},
-	0b10_111_000: {ALU, false, 0, nil},
-	0b10_111_001: {ALU, false, 0, nil},
-	0b10_111_010: {ALU, false, 0, nil},
-	0b10_111_011: {ALU, false, 0, nil},
-	0b10_111_100: {ALU, false, 0, nil},
-	0b10_111_101: {ALU, false, 0, nil},
-	0b10_111_110: {ALU, false, 0, nil},
-	0b10_111_111: {ALU, false, 0, nil},
+	0b10_000_000: {ALU, false, 0, 1, 0, alu}, // ADD A, B
+	0b10_000_001: {ALU, false, 0, 1, 0, alu}, // ADD A, C
+	0b10_000_010: {ALU, false, 0, 1, 0, alu}, // ADD A, D
+	0b10_000_011: {ALU, false, 0, 1, 0, alu}, // ADD A, E
+	0b10_000_100: {ALU, false, 0, 1, 0, alu}, // ADD A, H
+	0b10_000_101: {ALU, false, 0, 1, 0, alu}, // ADD A, L
+	0b10_000_110: {ALU, false, 0, 2, 0, alu}, // ADD A, (HL)
+	0b10_000_111: {ALU, false, 0, 1, 0, alu}, // ADD A, A
+	0b10_001_000: {ALU, false, 0, 1, 0, alu}, // ADC A, B
+	0b10_001_001: {ALU, false, 0, 1, 0, alu}, // ADC A, C
+	0b10_001_010: {ALU, false, 0, 1, 0, alu}, // ADC A, D
+	0b10_001_011: {ALU, false, 0, 1, 0, alu}, // ADC A, E
+	0b10_001_100: {ALU, false, 0, 1, 0, alu}, // ADC A, H
+	0b10_001_101: {ALU, false, 0, 1, 0, alu}, // ADC A, L
+	0b10_001_110: {ALU, false, 0, 2, 0, alu}, // ADC A, (HL)
+	0b10_001_111: {ALU, false, 0, 1, 0, alu}, // ADC A, A
+	0b10_010_000: {ALU, false, 0, 1, 0, alu}, // SUB B
+	0b10_010_001: {ALU, false, 0, 1, 0, alu}, // SUB C
+	0b10_010_010: {ALU, false, 0, 1, 0, alu}, // SUB D
+	0b10_010_011: {ALU, false, 0, 1, 0, alu}, // SUB E
+	0b10_010_100: {ALU, false, 0, 1, 0, alu}, // SUB H
+	0b10_010_101: {ALU, false, 0, 1, 0, alu}, // SUB L
+	0b10_010_110: {ALU, false, 0, 2, 0, alu}, // SUB (HL)
+	0b10_010_111: {ALU, false, 0, 1, 0, alu}, // SUB A
+	0b10_011_000: {ALU, false, 0, 1, 0, alu}, // SBC A, B
+	0b10_011_001: {ALU, false, 0, 1, 0, alu}, // SBC A, C
+	0b10_011_010: {ALU, false, 0, 1, 0, alu}, // SBC A, D
+	0b10_011_011: {ALU, false, 0, 1, 0, alu}, // SBC A, E
+	0b10_011_100: {ALU, false, 0, 1, 0, alu}, // SBC A, H
+	0b10_011_101: {ALU, false, 0, 1, 0, alu}, // SBC A, L
+	0b10_011_110: {ALU, false, 0, 2, 0, alu}, // SBC A, (HL)
+	0b10_011_111: {ALU, false, 0, 1, 0, alu}, // SBC A, A
+	0b10_100_000: {ALU, false, 0, 1, 0, alu}, // AND B
+	0b10_100_001: {ALU, false, 0, 1, 0, alu}, // AND C
+	0b10_100_010: {ALU, false, 0, 1, 0, alu}, // AND D
+	0b10_100_011: {ALU, false, 0, 1, 0, alu}, // AND E
+	0b10_100_100: {ALU, false, 0, 1, 0, alu}, // AND H
+	0b10_100_101: {ALU, false, 0, 1, 0, alu}, // AND L
+	0b10_100_110: {ALU, false, 0, 2, 0, alu}, // AND (HL)
+	0b10_100_111: {ALU, false, 0, 1, 0, alu}, // AND A
+	0b10_101_000: {ALU, false, 0, 1, 0, alu}, // XOR B
+	0b10_101_001: {ALU, false, 0, 1, 0, alu}, // XOR C
+	0b10_101_010: {ALU, false, 0, 1, 0, alu}, // XOR D
+	0b10_101_011: {ALU, false, 0, 1, 0, alu}, // XOR E
+	0b10_101_100: {ALU, false, 0, 1, 0, alu}, // XOR H
+	0b10_101_101: {ALU, false, 0, 1, 0, alu}, // XOR L
+	0b10_101_110: {ALU, false, 0, 2, 0, alu}, // XOR (HL)
+	0b10_101_111: {ALU, false, 0, 1, 0, alu}, // XOR A
+	0b10_110_000: {ALU, false, 0, 1, 0, alu}, // OR B
+	0b10_110_001: {ALU, false, 0, 1, 0, alu}, // OR C
+	0b10_110_010: {ALU, false, 0, 1, 0, alu}, // OR D
+	0b10_110_011: {ALU, false, 0, 1, 0, alu}, // OR E
+	0b10_110_100: {ALU, false, 0, 1, 0, alu}, // OR H
+	0b10_110_101: {ALU, false, 0, 1, 0, alu}, // OR L
+	0b10_110_110: {ALU, false, 0, 2, 0, alu}, // OR (HL)
+	0b10_110_111: {ALU, false, 0, 1, 0, alu}, // OR A
+	0b10_111_000: {ALU, false, 0, 1, 0, alu}, // CP B
+	0b10_111_001: {ALU, false, 0, 1, 0, alu}, // CP C
+	0b10_111_010: {ALU, false, 0, 1, 0, alu}, // CP D
+	0b10_111_011: {ALU, false, 0, 1, 0, alu}, // CP E
+	0b10_111_100: {ALU, false, 0, 1, 0, alu}, // CP H
+	0b10_111_101: {ALU, false, 0, 1, 0, alu}, // CP L
+	0b10_111_110: {ALU, false, 0, 2, 0, alu}, // CP (HL)
+	0b10_111_111: {ALU, false, 0, 1, 0, alu}, // CP A
 	//XX_YYY_ZZZ
 	//   PPQ
-	0b11_000_000: {RET, false, 0, nil},
-	0b11_001_000: {RET, false, 0, nil},
-	0b11_010_000: {RET, false, 0, nil},
-	0b11_011_000: {RET, false, 0, nil},
-	0b11_100_000: {LD, false, 1, ld}, // LD (0xFF00 + n), A
-	0b11_101_000: {ADD, true, 0, nil},
-	0b11_110_000: {LD, false, 1, nil},
-	0b11_111_000: {LD, true, 0, nil},
+	0b11_000_000: {RET, false, 0, 2, 5, ret}, // RET NZ
+	0b11_001_000: {RET, false, 0, 2, 5, ret}, // RET Z
+	0b11_010_000: {RET, false, 0, 2, 5, ret}, // RET NC
+	0b11_011_000: {RET, false, 0, 2, 5, ret}, // RET C
+	0b11_100_000: {LD, false, 1, 3, 0, ld},   // LD (0xFF00 + n), A
+	0b11_101_000: {ADD, true, 0, 4, 0, add},  // ADD SP, d
+	0b11_110_000: {LD, false, 1, 3, 0, ld},   // LD A, (0xFF00 + n)
+	0b11_111_000: {LD, true, 0, 3, 0, ld},    // LD HL, SP + d
 
-	0b11_000_001: {POP, false, 0, pop}, // POP BC
-	0b11_010_001: {POP, false, 0, pop}, // POP DE
-	0b11_100_001: {POP, false, 0, pop}, // POP HL
-	0b11_110_001: {POP, false, 0, pop}, // POP AF
+	0b11_000_001: {POP, false, 0, 3, 0, pop}, // POP BC
+	0b11_010_001: {POP, false, 0, 3, 0, pop}, // POP DE
+	0b11_100_001: {POP, false, 0, 3, 0, pop}, // POP HL
+	0b11_110_001: {POP, false, 0, 3, 0, pop}, // POP AF
 
-	0b11_001_001: {RET, false, 0, nil},
-	0b11_011_001: {RETI, false, 0, nil},
-	0b11_101_001: {JP, false, 0, nil},
-	0b11_111_001: {LD, false, 0, nil},
+	0b11_001_001: {RET, false, 0, 4, 4, ret},   // RET
+	0b11_011_001: {RETI, false, 0, 4, 4, reti}, // RETI
+	0b11_101_001: {JP, false, 0, 1, 1, jp},     // JP HL
+	0b11_111_001: {LD, false, 0, 2, 0, ld},     // LD SP, HL
 
-	0b11_000_010: {JP, false, 2, nil},
-	0b11_001_010: {JP, false, 2, nil},
-	0b11_010_010: {JP, false, 2, nil},
-	0b11_011_010: {JP, false, 2, nil},
-	0b11_100_010: {LD, false, 0, ld}, // LD ($FF00+C),A
-	0b11_101_010: {LD, false, 2, nil},
-	0b11_110_010: {LD, false, 0, nil},
-	0b11_111_010: {LD, false, 2, nil},
+	0b11_000_010: {JP, false, 2, 3, 4, jp}, // JP NZ, nn
+	0b11_001_010: {JP, false, 2, 3, 4, jp}, // JP Z, nn
+	0b11_010_010: {JP, false, 2, 3, 4, jp}, // JP NC, nn
+	0b11_011_010: {JP, false, 2, 3, 4, jp}, // JP C, nn
+	0b11_100_010: {LD, false, 0, 2, 0, ld}, // LD (0xFF00 + C), A
+	0b11_101_010: {LD, false, 2, 4, 0, ld}, // LD (nn), A
+	0b11_110_010: {LD, false, 0, 2, 0, ld}, // LD A, (0xFF00 + C)
+	0b11_111_010: {LD, false, 2, 4, 0, ld}, // LD A, (nn)
 
-	0b11_000_011: {JP, false, 2, nil},
+	0b11_000_011: {JP, false, 2, 4, 4, jp}, // JP nn
 	// gap for CB prefix and removed instructions
-	0b11_110_011: {DI, false, 0, nil},
-	0b11_111_011: {EI, false, 0, nil},
+	0b11_110_011: {DI, false, 0, 1, 0, di}, // DI
+	0b11_111_011: {EI, false, 0, 1, 0, ei}, // EI
 
-	0b11_000_100: {CALL, false, 2, nil},
-	0b11_001_100: {CALL, false, 2, nil},
-	0b11_010_100: {CALL, false, 2, nil},
-	0b11_011_100: {CALL, false, 2, nil},
+	0b11_000_100: {CALL, false, 2, 3, 6, call}, // CALL NZ, nn
+	0b11_001_100: {CALL, false, 2, 3, 6, call}, // CALL Z, nn
+	0b11_010_100: {CALL, false, 2, 3, 6, call}, // CALL NC, nn
+	0b11_011_100: {CALL, false, 2, 3, 6, call}, // CALL C, nn
 	// gap for removed instructions
 
-	0b11_000_101: {PUSH, false, 0, push}, // PUSH BC
-	0b11_010_101: {PUSH, false, 0, push}, // PUSH DE
-	0b11_100_101: {PUSH, false, 0, push}, // PUSH HL
-	0b11_110_101: {PUSH, false, 0, push}, // PUSH AF
+	0b11_000_101: {PUSH, false, 0, 4, 0, push}, // PUSH BC
+	0b11_010_101: {PUSH, false, 0, 4, 0, push}, // PUSH DE
+	0b11_100_101: {PUSH, false, 0, 4, 0, push}, // PUSH HL
+	0b11_110_101: {PUSH, false, 0, 4, 0, push}, // PUSH AF
 	//XX_YYY_ZZZ
 	//   PPQ
-	0b11_001_101: {CALL, false, 2, call}, // CALL nn
+	0b11_001_101: {CALL, false, 2, 6, 6, call}, // CALL nn
 	// gap for removed instructions
 
-	0b11_000_110: {ALU, false, 1, nil},
-	0b11_001_110: {ALU, false, 1, nil},
-	0b11_010_110: {ALU, false, 1, nil},
-	0b11_011_110: {ALU, false, 1, nil},
-	0b11_100_110: {ALU, false, 1, nil},
-	0b11_101_110: {ALU, false, 1, nil},
-	0b11_110_110: {ALU, false, 1, nil},
-	0b11_111_110: {ALU, false, 1, nil},
+	0b11_000_110: {ALU, false, 1, 2, 0, alu}, // ADD A, n
+	0b11_001_110: {ALU, false, 1, 2, 0, alu}, // ADC A, n
+	0b11_010_110: {ALU, false, 1, 2, 0, alu}, // SUB n
+	0b11_011_110: {ALU, false, 1, 2, 0, alu}, // SBC A, n
+	0b11_100_110: {ALU, false, 1, 2, 0, alu}, // AND n
+	0b11_101_110: {ALU, false, 1, 2, 0, alu}, // XOR n
+	0b11_110_110: {ALU, false, 1, 2, 0, alu}, // OR n
+	0b11_111_110: {ALU, false, 1, 2, 0, alu}, // CP n
 
-	0b11_000_111: {RST, false, 0, nil},
-	0b11_001_111: {RST, false, 0, nil},
-	0b11_010_111: {RST, false, 0, nil},
-	0b11_011_111: {RST, false, 0, nil},
-	0b11_100_111: {RST, false, 0, nil},
-	0b11_101_111: {RST, false, 0, nil},
-	0b11_110_111: {RST, false, 0, nil},
-	0b11_111_111: {RST, false, 0, nil},
+	0b11_000_111: {RST, false, 0, 4, 4, rst}, // RST 00H
+	0b11_001_111: {RST, false, 0, 4, 4, rst}, // RST 08H
+	0b11_010_111: {RST, false, 0, 4, 4, rst}, // RST 10H
+	0b11_011_111: {RST, false, 0, 4, 4, rst}, // RST 18H
+	0b11_100_111: {RST, false, 0, 4, 4, rst}, // RST 20H
+	0b11_101_111: {RST, false, 0, 4, 4, rst}, // RST 28H
+	0b11_110_111: {RST, false, 0, 4, 4, rst}, // RST 30H
+	0b11_111_111: {RST, false, 0, 4, 4, rst}, // RST 38H
 }
 
 var cb = map[byte]OpBytes{
 	//XX_YYY_ZZZ
 	//   PPQ
-	0b00_000_000: {ROT, false, 0, nil},
-	0b00_000_001: {ROT, false, 0, nil},
-	0b00_000_010: {ROT, false, 0, nil},
-	0b00_000_011: {ROT, false, 0, nil},
-	0b00_000_100: {ROT, false, 0, nil},
-	0b00_000_101: {ROT, false, 0, nil},
-	0b00_000_110: {ROT, false, 0, nil},
-	0b00_000_111: {ROT, false, 0, nil},
-	0b00_001_000: {ROT, false, 0, nil},
-	0b00_001_001: {ROT, false, 0, nil},
-	0b00_001_010: {ROT, false, 0, nil},
-	0b00_001_011: {ROT, false, 0, nil},
-	0b00_001_100: {ROT, false, 0, nil},
-	0b00_001_101: {ROT, false, 0, nil},
-	0b00_001_110: {ROT, false, 0, nil},
-	0b00_001_111: {ROT, false, 0, nil},
-	0b00_010_000: {ROT, false, 0, nil},
-	0b00_010_001: {ROT, false, 0, rl}, // RL C
-	0b00_010_010: {ROT, false, 0, nil},
-	0b00_010_011: {ROT, false, 0, nil},
-	0b00_010_100: {ROT, false, 0, nil},
-	0b00_010_101: {ROT, false, 0, nil},
-	0b00_010_110: {ROT, false, 0, nil},
-	0b00_010_111: {ROT, false, 0, nil},
-	0b00_011_000: {ROT, false, 0, nil},
-	0b00_011_001: {ROT, false, 0, nil},
-	0b00_011_010: {ROT, false, 0, nil},
-	0b00_011_011: {ROT, false, 0, nil},
-	0b00_011_100: {ROT, false, 0, nil},
-	0b00_011_101: {ROT, false, 0, nil},
-	0b00_011_110: {ROT, false, 0, nil},
-	0b00_011_111: {ROT, false, 0, nil},
-	0b00_100_000: {ROT, false, 0, nil},
-	0b00_100_001: {ROT, false, 0, nil},
-	0b00_100_010: {ROT, false, 0, nil},
-	0b00_100_011: {ROT, false, 0, nil},
-	0b00_100_100: {ROT, false, 0, nil},
-	0b00_100_101: {ROT, false, 0, nil},
-	0b00_100_110: {ROT, false, 0, nil},
-	0b00_100_111: {ROT, false, 0, nil},
-	0b00_101_000: {ROT, false, 0, nil},
-	0b00_101_001: {ROT, false, 0, nil},
-	0b00_101_010: {ROT, false, 0, nil},
-	0b00_101_011: {ROT, false, 0, nil},
-	0b00_101_100: {ROT, false, 0, nil},
-	0b00_101_101: {ROT, false, 0, nil},
-	0b00_101_110: {ROT, false, 0, nil},
-	0b00_101_111: {ROT, false, 0, nil},
-	0b00_110_000: {ROT, false, 0, nil},
-	0b00_110_001: {ROT, false, 0, nil},
-	0b00_110_010: {ROT, false, 0, nil},
-	0b00_110_011: {ROT, false, 0, nil},
-	0b00_110_100: {ROT, false, 0, nil},
-	0b00_110_101: {ROT, false, 0, nil},
-	0b00_110_110: {ROT, false, 0, nil},
-	0b00_110_111: {ROT, false, 0, nil},
-	0b00_111_000: {ROT, false, 0, nil},
-	0b00_111_001: {ROT, false, 0, nil},
-	0b00_111_010: {ROT, false, 0, nil},
-	0b00_111_011: {ROT, false, 0, nil},
-	0b00_111_100: {ROT, false, 0, nil},
-	0b00_111_101: {ROT, false, 0, nil},
-	0b00_111_110: {ROT, false, 0, nil},
-	0b00_111_111: {ROT, false, 0, nil},
+	0b00_000_000: {ROT, false, 0, 2, 0, rot}, // RLC B
+	0b00_000_001: {ROT, false, 0, 2, 0, rot}, // RLC C
+	0b00_000_010: {ROT, false, 0, 2, 0, rot}, // RLC D
+	0b00_000_011: {ROT, false, 0, 2, 0, rot}, // RLC E
+	0b00_000_100: {ROT, false, 0, 2, 0, rot}, // RLC H
+	0b00_000_101: {ROT, false, 0, 2, 0, rot}, // RLC L
+	0b00_000_110: {ROT, false, 0, 4, 0, rot}, // RLC (HL)
+	0b00_000_111: {ROT, false, 0, 2, 0, rot}, // RLC A
+	0b00_001_000: {ROT, false, 0, 2, 0, rot}, // RRC B
+	0b00_001_001: {ROT, false, 0, 2, 0, rot}, // RRC C
+	0b00_001_010: {ROT, false, 0, 2, 0, rot}, // RRC D
+	0b00_001_011: {ROT, false, 0, 2, 0, rot}, // RRC E
+	0b00_001_100: {ROT, false, 0, 2, 0, rot}, // RRC H
+	0b00_001_101: {ROT, false, 0, 2, 0, rot}, // RRC L
+	0b00_001_110: {ROT, false, 0, 4, 0, rot}, // RRC (HL)
+	0b00_001_111: {ROT, false, 0, 2, 0, rot}, // RRC A
+	0b00_010_000: {ROT, false, 0, 2, 0, rot}, // RL B
+	0b00_010_001: {ROT, false, 0, 2, 0, rot}, // RL C
+	0b00_010_010: {ROT, false, 0, 2, 0, rot}, // RL D
+	0b00_010_011: {ROT, false, 0, 2, 0, rot}, // RL E
+	0b00_010_100: {ROT, false, 0, 2, 0, rot}, // RL H
+	0b00_010_101: {ROT, false, 0, 2, 0, rot}, // RL L
+	0b00_010_110: {ROT, false, 0, 4, 0, rot}, // RL (HL)
+	0b00_010_111: {ROT, false, 0, 2, 0, rot}, // RL A
+	0b00_011_000: {ROT, false, 0, 2, 0, rot}, // RR B
+	0b00_011_001: {ROT, false, 0, 2, 0, rot}, // RR C
+	0b00_011_010: {ROT, false, 0, 2, 0, rot}, // RR D
+	0b00_011_011: {ROT, false, 0, 2, 0, rot}, // RR E
+	0b00_011_100: {ROT, false, 0, 2, 0, rot}, // RR H
+	0b00_011_101: {ROT, false, 0, 2, 0, rot}, // RR L
+	0b00_011_110: {ROT, false, 0, 4, 0, rot}, // RR (HL)
+	0b00_011_111: {ROT, false, 0, 2, 0, rot}, // RR A
+	0b00_100_000: {ROT, false, 0, 2, 0, rot}, // SLA B
+	0b00_100_001: {ROT, false, 0, 2, 0, rot}, // SLA C
+	0b00_100_010: {ROT, false, 0, 2, 0, rot}, // SLA D
+	0b00_100_011: {ROT, false, 0, 2, 0, rot}, // SLA E
+	0b00_100_100: {ROT, false, 0, 2, 0, rot}, // SLA H
+	0b00_100_101: {ROT, false, 0, 2, 0, rot}, // SLA L
+	0b00_100_110: {ROT, false, 0, 4, 0, rot}, // SLA (HL)
+	0b00_100_111: {ROT, false, 0, 2, 0, rot}, // SLA A
+	0b00_101_000: {ROT, false, 0, 2, 0, rot}, // SRA B
+	0b00_101_001: {ROT, false, 0, 2, 0, rot}, // SRA C
+	0b00_101_010: {ROT, false, 0, 2, 0, rot}, // SRA D
+	0b00_101_011: {ROT, false, 0, 2, 0, rot}, // SRA E
+	0b00_101_100: {ROT, false, 0, 2, 0, rot}, // SRA H
+	0b00_101_101: {ROT, false, 0, 2, 0, rot}, // SRA L
+	0b00_101_110: {ROT, false, 0, 4, 0, rot}, // SRA (HL)
+	0b00_101_111: {ROT, false, 0, 2, 0, rot}, // SRA A
+	0b00_110_000: {ROT, false, 0, 2, 0, rot}, // SWAP B
+	0b00_110_001: {ROT, false, 0, 2, 0, rot}, // SWAP C
+	0b00_110_010: {ROT, false, 0, 2, 0, rot}, // SWAP D
+	0b00_110_011: {ROT, false, 0, 2, 0, rot}, // SWAP E
+	0b00_110_100: {ROT, false, 0, 2, 0, rot}, // SWAP H
+	0b00_110_101: {ROT, false, 0, 2, 0, rot}, // SWAP L
+	0b00_110_110: {ROT, false, 0, 4, 0, rot}, // SWAP (HL)
+	0b00_110_111: {ROT, false, 0, 2, 0, rot}, // SWAP A
+	0b00_111_000: {ROT, false, 0, 2, 0, rot}, // SRL B
+	0b00_111_001: {ROT, false, 0, 2, 0, rot}, // SRL C
+	0b00_111_010: {ROT, false, 0, 2, 0, rot}, // SRL D
+	0b00_111_011: {ROT, false, 0, 2, 0, rot}, // SRL E
+	0b00_111_100: {ROT, false, 0, 2, 0, rot}, // SRL H
+	0b00_111_101: {ROT, false, 0, 2, 0, rot}, // SRL L
+	0b00_111_110: {ROT, false, 0, 4, 0, rot}, // SRL (HL)
+	0b00_111_111: {ROT, false, 0, 2, 0, rot}, // SRL A
 	//XX_YYY_ZZZ
 	//   PPQ
-	0b01_000_000: {BIT, false, 0, bit}, // BIT 0, B
-	0b01_000_001: {BIT, false, 0, bit}, // BIT 0, C
-	0b01_000_010: {BIT, false, 0, bit}, // BIT 0, D
-	0b01_000_011: {BIT, false, 0, bit}, // BIT 0, E
-	0b01_000_100: {BIT, false, 0, bit}, // BIT 0, H
-	0b01_000_101: {BIT, false, 0, bit}, // BIT 0, L
-	0b01_000_110: {BIT, false, 0, bit}, // BIT 0, (HL)
-	0b01_000_111: {BIT, false, 0, bit}, // BIT 0, A
-	0b01_001_000: {BIT, false, 0, bit}, // BIT 1, B
-	0b01_001_001: {BIT, false, 0, bit}, // BIT 1, C
-	0b01_001_010: {BIT, false, 0, bit}, // BIT 1, D
-	0b01_001_011: {BIT, false, 0, bit}, // BIT 1, E
-	0b01_001_100: {BIT, false, 0, bit}, // BIT 1, H
-	0b01_001_101: {BIT, false, 0, bit}, // BIT 1, L
-	0b01_001_110: {BIT, false, 0, bit}, // BIT 1, (HL)
-	0b01_001_111: {BIT, false, 0, bit}, // BIT 1, A
-	0b01_010_000: {BIT, false, 0, bit}, // BIT 2, B
-	0b01_010_001: {BIT, false, 0, bit}, // BIT 2, C
-	0b01_010_010: {BIT, false, 0, bit}, // BIT 2, D
-	0b01_010_011: {BIT, false, 0, bit}, // BIT 2, E
-	0b01_010_100: {BIT, false, 0, bit}, // BIT 2, H
-	0b01_010_101: {BIT, false, 0, bit}, // BIT 2, L
-	0b01_010_110: {BIT, false, 0, bit}, // BIT 2, (HL)
-	0b01_010_111: {BIT, false, 0, bit}, // BIT 2, A
-	0b01_011_000: {BIT, false, 0, bit}, // BIT 3, B
-	0b01_011_001: {BIT, false, 0, bit}, // BIT 3, C
-	0b01_011_010: {BIT, false, 0, bit}, // BIT 3, D
-	0b01_011_011: {BIT, false, 0, bit}, // BIT 3, E
-	0b01_011_100: {BIT, false, 0, bit}, // BIT 3, H
-	0b01_011_101: {BIT, false, 0, bit}, // BIT 3, L
-	0b01_011_110: {BIT, false, 0, bit}, // BIT 3, (HL)
-	0b01_011_111: {BIT, false, 0, bit}, // BIT 3, A
-	0b01_100_000: {BIT, false, 0, bit}, // BIT 4, B
-	0b01_100_001: {BIT, false, 0, bit}, // BIT 4, C
-	0b01_100_010: {BIT, false, 0, bit}, // BIT 4, D
-	0b01_100_011: {BIT, false, 0, bit}, // BIT 4, E
-	0b01_100_100: {BIT, false, 0, bit}, // BIT 4, H
-	0b01_100_101: {BIT, false, 0, bit}, // BIT 4, L
-	0b01_100_110: {BIT, false, 0, bit}, // BIT 4, (HL)
-	0b01_100_111: {BIT, false, 0, bit}, // BIT 4, A
-	0b01_101_000: {BIT, false, 0, bit}, // BIT 5, B
-	0b01_101_001: {BIT, false, 0, bit}, // BIT 5, C
-	0b01_101_010: {BIT, false, 0, bit}, // BIT 5, D
-	0b01_101_011: {BIT, false, 0, bit}, // BIT 5, E
-	0b01_101_100: {BIT, false, 0, bit}, // BIT 5, H
-	0b01_101_101: {BIT, false, 0, bit}, // BIT 5, L
-	0b01_101_110: {BIT, false, 0, bit}, // BIT 5, (HL)
-	0b01_101_111: {BIT, false, 0, bit}, // BIT 5, A
-	0b01_110_000: {BIT, false, 0, bit}, // BIT 6, B
-	0b01_110_001: {BIT, false, 0, bit}, // BIT 6, C
-	0b01_110_010: {BIT, false, 0, bit}, // BIT 6, D
-	0b01_110_011: {BIT, false, 0, bit}, // BIT 6, E
-	0b01_110_100: {BIT, false, 0, bit}, // BIT 6, H
-	0b01_110_101: {BIT, false, 0, bit}, // BIT 6, L
-	0b01_110_110: {BIT, false, 0, bit}, // BIT 6, (HL)
-	0b01_110_111: {BIT, false, 0, bit}, // BIT 6, A
-	0b01_111_000: {BIT, false, 0, bit}, // BIT 7, B
-	0b01_111_001: {BIT, false, 0, bit}, // BIT 7, C
-	0b01_111_010: {BIT, false, 0, bit}, // BIT 7, D
-	0b01_111_011: {BIT, false, 0, bit}, // BIT 7, E
-	0b01_111_100: {BIT, false, 0, bit}, // BIT 7, H
-	0b01_111_101: {BIT, false, 0, bit}, // BIT 7, L
-	0b01_111_110: {BIT, false, 0, bit}, // BIT 7, (HL)
-	0b01_111_111: {BIT, false, 0, bit}, // BIT 7, A
+	0b01_000_000: {BIT, false, 0, 2, 0, bit}, // BIT 0, B
+	0b01_000_001: {BIT, false, 0, 2, 0, bit}, // BIT 0, C
+	0b01_000_010: {BIT, false, 0, 2, 0, bit}, // BIT 0, D
+	0b01_000_011: {BIT, false, 0, 2, 0, bit}, // BIT 0, E
+	0b01_000_100: {BIT, false, 0, 2, 0, bit}, // BIT 0, H
+	0b01_000_101: {BIT, false, 0, 2, 0, bit}, // BIT 0, L
+	0b01_000_110: {BIT, false, 0, 3, 0, bit}, // BIT 0, (HL)
+	0b01_000_111: {BIT, false, 0, 2, 0, bit}, // BIT 0, A
+	0b01_001_000: {BIT, false, 0, 2, 0, bit}, // BIT 1, B
+	0b01_001_001: {BIT, false, 0, 2, 0, bit}, // BIT 1, C
+	0b01_001_010: {BIT, false, 0, 2, 0, bit}, // BIT 1, D
+	0b01_001_011: {BIT, false, 0, 2, 0, bit}, // BIT 1, E
+	0b01_001_100: {BIT, false, 0, 2, 0, bit}, // BIT 1, H
+	0b01_001_101: {BIT, false, 0, 2, 0, bit}, // BIT 1, L
+	0b01_001_110: {BIT, false, 0, 3, 0, bit}, // BIT 1, (HL)
+	0b01_001_111: {BIT, false, 0, 2, 0, bit}, // BIT 1, A
+	0b01_010_000: {BIT, false, 0, 2, 0, bit}, // BIT 2, B
+	0b01_010_001: {BIT, false, 0, 2, 0, bit}, // BIT 2, C
+	0b01_010_010: {BIT, false, 0, 2, 0, bit}, // BIT 2, D
+	0b01_010_011: {BIT, false, 0, 2, 0, bit}, // BIT 2, E
+	0b01_010_100: {BIT, false, 0, 2, 0, bit}, // BIT 2, H
+	0b01_010_101: {BIT, false, 0, 2, 0, bit}, // BIT 2, L
+	0b01_010_110: {BIT, false, 0, 3, 0, bit}, // BIT 2, (HL)
+	0b01_010_111: {BIT, false, 0, 2, 0, bit}, // BIT 2, A
+	0b01_011_000: {BIT, false, 0, 2, 0, bit}, // BIT 3, B
+	0b01_011_001: {BIT, false, 0, 2, 0, bit}, // BIT 3, C
+	0b01_011_010: {BIT, false, 0, 2, 0, bit}, // BIT 3, D
+	0b01_011_011: {BIT, false, 0, 2, 0, bit}, // BIT 3, E
+	0b01_011_100: {BIT, false, 0, 2, 0, bit}, // BIT 3, H
+	0b01_011_101: {BIT, false, 0, 2, 0, bit}, // BIT 3, L
+	0b01_011_110: {BIT, false, 0, 3, 0, bit}, // BIT 3, (HL)
+	0b01_011_111: {BIT, false, 0, 2, 0, bit}, // BIT 3, A
+	0b01_100_000: {BIT, false, 0, 2, 0, bit}, // BIT 4, B
+	0b01_100_001: {BIT, false, 0, 2, 0, bit}, // BIT 4, C
+	0b01_100_010: {BIT, false, 0, 2, 0, bit}, // BIT 4, D
+	0b01_100_011: {BIT, false, 0, 2, 0, bit}, // BIT 4, E
+	0b01_100_100: {BIT, false, 0, 2, 0, bit}, // BIT 4, H
+	0b01_100_101: {BIT, false, 0, 2, 0, bit}, // BIT 4, L
+	0b01_100_110: {BIT, false, 0, 3, 0, bit}, // BIT 4, (HL)
+	0b01_100_111: {BIT, false, 0, 2, 0, bit}, // BIT 4, A
+	0b01_101_000: {BIT, false, 0, 2, 0, bit}, // BIT 5, B
+	0b01_101_001: {BIT, false, 0, 2, 0, bit}, // BIT 5, C
+	0b01_101_010: {BIT, false, 0, 2, 0, bit}, // BIT 5, D
+	0b01_101_011: {BIT, false, 0, 2, 0, bit}, // BIT 5, E
+	0b01_101_100: {BIT, false, 0, 2, 0, bit}, // BIT 5, H
+	0b01_101_101: {BIT, false, 0, 2, 0, bit}, // BIT 5, L
+	0b01_101_110: {BIT, false, 0, 3, 0, bit}, // BIT 5, (HL)
+	0b01_101_111: {BIT, false, 0, 2, 0, bit}, // BIT 5, A
+	0b01_110_000: {BIT, false, 0, 2, 0, bit}, // BIT 6, B
+	0b01_110_001: {BIT, false, 0, 2, 0, bit}, // BIT 6, C
+	0b01_110_010: {BIT, false, 0, 2, 0, bit}, // BIT 6, D
+	0b01_110_011: {BIT, false, 0, 2, 0, bit}, // BIT 6, E
+	0b01_110_100: {BIT, false, 0, 2, 0, bit}, // BIT 6, H
+	0b01_110_101: {BIT, false, 0, 2, 0, bit}, // BIT 6, L
+	0b01_110_110: {BIT, false, 0, 3, 0, bit}, // BIT 6, (HL)
+	0b01_110_111: {BIT, false, 0, 2, 0, bit}, // BIT 6, A
+	0b01_111_000: {BIT, false, 0, 2, 0, bit}, // BIT 7, B
+	0b01_111_001: {BIT, false, 0, 2, 0, bit}, // BIT 7, C
+	0b01_111_010: {BIT, false, 0, 2, 0, bit}, // BIT 7, D
+	0b01_111_011: {BIT, false, 0, 2, 0, bit}, // BIT 7, E
+	0b01_111_100: {BIT, false, 0, 2, 0, bit}, // BIT 7, H
+	0b01_111_101: {BIT, false, 0, 2, 0, bit}, // BIT 7, L
+	0b01_111_110: {BIT, false, 0, 3, 0, bit}, // BIT 7, (HL)
+	0b01_111_111: {BIT, false, 0, 2, 0, bit}, // BIT 7, A
 	//XX_YYY_ZZZ
 	//   PPQ
-	0b10_000_000: {RES, false, 0, nil},
-	0b10_000_001: {RES, false, 0, nil},
-	0b10_000_010: {RES, false, 0, nil},
-	0b10_000_011: {RES, false, 0, nil},
-	0b10_000_100: {RES, false, 0, nil},
-	0b10_000_101: {RES, false, 0, nil},
-	0b10_000_110: {RES, false, 0, nil},
-	0b10_000_111: {RES, false, 0, nil},
-	0b10_001_000: {RES, false, 0, nil},
-	0b10_001_001: {RES, false, 0, nil},
-	0b10_001_010: {RES, false, 0, nil},
-	0b10_001_011: {RES, false, 0, nil},
-	0b10_001_100: {RES, false, 0, nil},
-	0b10_001_101: {RES, false, 0, nil},
-	0b10_001_110: {RES, false, 0, nil},
-	0b10_001_111: {RES, false, 0, nil},
-	0b10_010_000: {RES, false, 0, nil},
-	0b10_010_001: {RES, false, 0, nil},
-	0b10_010_010: {RES, false, 0, nil},
-	0b10_010_011: {RES, false, 0, nil},
-	0b10_010_100: {RES, false, 0, nil},
-	0b10_010_101: {RES, false, 0, nil},
-	0b10_010_110: {RES, false, 0, nil},
-	0b10_010_111: {RES, false, 0, nil},
-	0b10_011_000: {RES, false, 0, nil},
-	0b10_011_001: {RES, false, 0, nil},
-	0b10_011_010: {RES, false, 0, nil},
-	0b10_011_011: {RES, false, 0, nil},
-	0b10_011_100: {RES, false, 0, nil},
-	0b10_011_101: {RES, false, 0, nil},
-	0b10_011_110: {RES, false, 0, nil},
-	0b10_011_111: {RES, false, 0, nil},
-	0b10_100_000: {RES, false, 0, nil},
-	0b10_100_001: {RES, false, 0, nil},
-	0b10_100_010: {RES, false, 0, nil},
-	0b10_100_011: {RES, false, 0, nil},
-	0b10_100_100: {RES, false, 0, nil},
-	0b10_100_101: {RES, false, 0, nil},
-	0b10_100_110: {RES, false, 0, nil},
-	0b10_100_111: {RES, false, 0, nil},
-	0b10_101_000: {RES, false, 0, nil},
-	0b10_101_001: {RES, false, 0, nil},
-	0b10_101_010: {RES, false, 0, nil},
-	0b10_101_011: {RES, false, 0, nil},
-	0b10_101_100: {RES, false, 0, nil},
-	0b10_101_101: {RES, false, 0, nil},
-	0b10_101_110: {RES, false, 0, nil},
-	0b10_101_111: {RES, false, 0, nil},
-	0b10_110_000: {RES, false, 0, nil},
-	0b10_110_001: {RES, false, 0, nil},
-	0b10_110_010: {RES, false, 0, nil},
-	0b10_110_011: {RES, false, 0, nil},
-	0b10_110_100: {RES, false, 0, nil},
-	0b10_110_101: {RES, false, 0, nil},
-	0b10_110_110: {RES, false, 0, nil},
-	0b10_110_111: {RES, false, 0, nil},
-	0b10_111_000: {RES, false, 0, nil},
-	0b10_111_001: {RES, false, 0, nil},
-	0b10_111_010: {RES, false, 0, nil},
-	0b10_111_011: {RES, false, 0, nil},
-	0b10_111_100: {RES, false, 0, nil},
-	0b10_111_101: {RES, false, 0, nil},
-	0b10_111_110: {RES, false, 0, nil},
-	0b10_111_111: {RES, false, 0, nil},
+	0b10_000_000: {RES, false, 0, 2, 0, res}, // RES 0, B
+	0b10_000_001: {RES, false, 0, 2, 0, res}, // RES 0, C
+	0b10_000_010: {RES, false, 0, 2, 0, res}, // RES 0, D
+	0b10_000_011: {RES, false, 0, 2, 0, res}, // RES 0, E
+	0b10_000_100: {RES, false, 0, 2, 0, res}, // RES 0, H
+	0b10_000_101: {RES, false, 0, 2, 0, res}, // RES 0, L
+	0b10_000_110: {RES, false, 0, 4, 0, res}, // RES 0, (HL)
+	0b10_000_111: {RES, false, 0, 2, 0, res}, // RES 0, A
+	0b10_001_000: {RES, false, 0, 2, 0, res}, // RES 1, B
+	0b10_001_001: {RES, false, 0, 2, 0, res}, // RES 1, C
+	0b10_001_010: {RES, false, 0, 2, 0, res}, // RES 1, D
+	0b10_001_011: {RES, false, 0, 2, 0, res}, // RES 1, E
+	0b10_001_100: {RES, false, 0, 2, 0, res}, // RES 1, H
+	0b10_001_101: {RES, false, 0, 2, 0, res}, // RES 1, L
+	0b10_001_110: {RES, false, 0, 4, 0, res}, // RES 1, (HL)
+	0b10_001_111: {RES, false, 0, 2, 0, res}, // RES 1, A
+	0b10_010_000: {RES, false, 0, 2, 0, res}, // RES 2, B
+	0b10_010_001: {RES, false, 0, 2, 0, res}, // RES 2, C
+	0b10_010_010: {RES, false, 0, 2, 0, res}, // RES 2, D
+	0b10_010_011: {RES, false, 0, 2, 0, res}, // RES 2, E
+	0b10_010_100: {RES, false, 0, 2, 0, res}, // RES 2, H
+	0b10_010_101: {RES, false, 0, 2, 0, res}, // RES 2, L
+	0b10_010_110: {RES, false, 0, 4, 0, res}, // RES 2, (HL)
+	0b10_010_111: {RES, false, 0, 2, 0, res}, // RES 2, A
+	0b10_011_000: {RES, false, 0, 2, 0, res}, // RES 3, B
+	0b10_011_001: {RES, false, 0, 2, 0, res}, // RES 3, C
+	0b10_011_010: {RES, false, 0, 2, 0, res}, // RES 3, D
+	0b10_011_011: {RES, false, 0, 2, 0, res}, // RES 3, E
+	0b10_011_100: {RES, false, 0, 2, 0, res}, // RES 3, H
+	0b10_011_101: {RES, false, 0, 2, 0, res}, // RES 3, L
+	0b10_011_110: {RES, false, 0, 4, 0, res}, // RES 3, (HL)
+	0b10_011_111: {RES, false, 0, 2, 0, res}, // RES 3, A
+	0b10_100_000: {RES, false, 0, 2, 0, res}, // RES 4, B
+	0b10_100_001: {RES, false, 0, 2, 0, res}, // RES 4, C
+	0b10_100_010: {RES, false, 0, 2, 0, res}, // RES 4, D
+	0b10_100_011: {RES, false, 0, 2, 0, res}, // RES 4, E
+	0b10_100_100: {RES, false, 0, 2, 0, res}, // RES 4, H
+	0b10_100_101: {RES, false, 0, 2, 0, res}, // RES 4, L
+	0b10_100_110: {RES, false, 0, 4, 0, res}, // RES 4, (HL)
+	0b10_100_111: {RES, false, 0, 2, 0, res}, // RES 4, A
+	0b10_101_000: {RES, false, 0, 2, 0, res}, // RES 5, B
+	0b10_101_001: {RES, false, 0, 2, 0, res}, // RES 5, C
+	0b10_101_010: {RES, false, 0, 2, 0, res}, // RES 5, D
+	0b10_101_011: {RES, false, 0, 2, 0, res}, // RES 5, E
+	0b10_101_100: {RES, false, 0, 2, 0, res}, // RES 5, H
+	0b10_101_101: {RES, false, 0, 2, 0, res}, // RES 5, L
+	0b10_101_110: {RES, false, 0, 4, 0, res}, // RES 5, (HL)
+	0b10_101_111: {RES, false, 0, 2, 0, res}, // RES 5, A
+	0b10_110_000: {RES, false, 0, 2, 0, res}, // RES 6, B
+	0b10_110_001: {RES, false, 0, 2, 0, res}, // RES 6, C
+	0b10_110_010: {RES, false, 0, 2, 0, res}, // RES 6, D
+	0b10_110_011: {RES, false, 0, 2, 0, res}, // RES 6, E
+	0b10_110_100: {RES, false, 0, 2, 0, res}, // RES 6, H
+	0b10_110_101: {RES, false, 0, 2, 0, res}, // RES 6, L
+	0b10_110_110: {RES, false, 0, 4, 0, res}, // RES 6, (HL)
+	0b10_110_111: {RES, false, 0, 2, 0, res}, // RES 6, A
+	0b10_111_000: {RES, false, 0, 2, 0, res}, // RES 7, B
+	0b10_111_001: {RES, false, 0, 2, 0, res}, // RES 7, C
+	0b10_111_010: {RES, false, 0, 2, 0, res}, // RES 7, D
+	0b10_111_011: {RES, false, 0, 2, 0, res}, // RES 7, E
+	0b10_111_100: {RES, false, 0, 2, 0, res}, // RES 7, H
+	0b10_111_101: {RES, false, 0, 2, 0, res}, // RES 7, L
+	0b10_111_110: {RES, false, 0, 4, 0, res}, // RES 7, (HL)
+	0b10_111_111: {RES, false, 0, 2, 0, res}, // RES 7, A
 	//XX_YYY_ZZZ
 	//   PPQ
-	0b11_000_000: {SET, false, 0, nil},
-	0b11_000_001: {SET, false, 0, nil},
-	0b11_000_010: {SET, false, 0, nil},
-	0b11_000_011: {SET, false, 0, nil},
-	0b11_000_100: {SET, false, 0, nil},
-	0b11_000_101: {SET, false, 0, nil},
-	0b11_000_110: {SET, false, 0, nil},
-	0b11_000_111: {SET, false, 0, nil},
-	0b11_001_000: {SET, false, 0, nil},
-	0b11_001_001: {SET, false, 0, nil},
-	0b11_001_010: {SET, false, 0, nil},
-	0b11_001_011: {SET, false, 0, nil},
-	0b11_001_100: {SET, false, 0, nil},
-	0b11_001_101: {SET, false, 0, nil},
-	0b11_001_110: {SET, false, 0, nil},
-	0b11_001_111: {SET, false, 0, nil},
-	0b11_010_000: {SET, false, 0, nil},
-	0b11_010_001: {SET, false, 0, nil},
-	0b11_010_010: {SET, false, 0, nil},
-	0b11_010_011: {SET, false, 0, nil},
-	0b11_010_100: {SET, false, 0, nil},
-	0b11_010_101: {SET, false, 0, nil},
-	0b11_010_110: {SET, false, 0, nil},
-	0b11_010_111: {SET, false, 0, nil},
-	0b11_011_000: {SET, false, 0, nil},
-	0b11_011_001: {SET, false, 0, nil},
-	0b11_011_010: {SET, false, 0, nil},
-	0b11_011_011: {SET, false, 0, nil},
-	0b11_011_100: {SET, false, 0, nil},
-	0b11_011_101: {SET, false, 0, nil},
-	0b11_011_110: {SET, false, 0, nil},
-	0b11_011_111: {SET, false, 0, nil},
-	0b11_100_000: {SET, false, 0, nil},
-	0b11_100_001: {SET, false, 0, nil},
-	0b11_100_010: {SET, false, 0, nil},
-	0b11_100_011: {SET, false, 0, nil},
-	0b11_100_100: {SET, false, 0, nil},
-	0b11_100_101: {SET, false, 0, nil},
-	0b11_100_110: {SET, false, 0, nil},
-	0b11_100_111: {SET, false, 0, nil},
-	0b11_101_000: {SET, false, 0, nil},
-	0b11_101_001: {SET, false, 0, nil},
-	0b11_101_010: {SET, false, 0, nil},
-	0b11_101_011: {SET, false, 0, nil},
-	0b11_101_100: {SET, false, 0, nil},
-	0b11_101_101: {SET, false, 0, nil},
-	0b11_101_110: {SET, false, 0, nil},
-	0b11_101_111: {SET, false, 0, nil},
-	0b11_110_000: {SET, false, 0, nil},
-	0b11_110_001: {SET, false, 0, nil},
-	0b11_110_010: {SET, false, 0, nil},
-	0b11_110_011: {SET, false, 0, nil},
-	0b11_110_100: {SET, false, 0, nil},
-	0b11_110_101: {SET, false, 0, nil},
-	0b11_110_110: {SET, false, 0, nil},
-	0b11_110_111: {SET, false, 0, nil},
-	0b11_111_000: {SET, false, 0, nil},
-	0b11_111_001: {SET, false, 0, nil},
-	0b11_111_010: {SET, false, 0, nil},
-	0b11_111_011: {SET, false, 0, nil},
-	0b11_111_100: {SET, false, 0, nil},
-	0b11_111_101: {SET, false, 0, nil},
-	0b11_111_110: {SET, false, 0, nil},
-	0b11_111_111: {SET, false, 0, nil},
+	0b11_000_000: {SET, false, 0, 2, 0, set}, // SET 0, B
+	0b11_000_001: {SET, false, 0, 2, 0, set}, // SET 0, C
+	0b11_000_010: {SET, false, 0, 2, 0, set}, // SET 0, D
+	0b11_000_011: {SET, false, 0, 2, 0, set}, // SET 0, E
+	0b11_000_100: {SET, false, 0, 2, 0, set}, // SET 0, H
+	0b11_000_101: {SET, false, 0, 2, 0, set}, // SET 0, L
+	0b11_000_110: {SET, false, 0, 4, 0, set}, // SET 0, (HL)
+	0b11_000_111: {SET, false, 0, 2, 0, set}, // SET 0, A
+	0b11_001_000: {SET, false, 0, 2, 0, set}, // SET 1, B
+	0b11_001_001: {SET, false, 0, 2, 0, set}, // SET 1, C
+	0b11_001_010: {SET, false, 0, 2, 0, set}, // SET 1, D
+	0b11_001_011: {SET, false, 0, 2, 0, set}, // SET 1, E
+	0b11_001_100: {SET, false, 0, 2, 0, set}, // SET 1, H
+	0b11_001_101: {SET, false, 0, 2, 0, set}, // SET 1, L
+	0b11_001_110: {SET, false, 0, 4, 0, set}, // SET 1, (HL)
+	0b11_001_111: {SET, false, 0, 2, 0, set}, // SET 1, A
+	0b11_010_000: {SET, false, 0, 2, 0, set}, // SET 2, B
+	0b11_010_001: {SET, false, 0, 2, 0, set}, // SET 2, C
+	0b11_010_010: {SET, false, 0, 2, 0, set}, // SET 2, D
+	0b11_010_011: {SET, false, 0, 2, 0, set}, // SET 2, E
+	0b11_010_100: {SET, false, 0, 2, 0, set}, // SET 2, H
+	0b11_010_101: {SET, false, 0, 2, 0, set}, // SET 2, L
+	0b11_010_110: {SET, false, 0, 4, 0, set}, // SET 2, (HL)
+	0b11_010_111: {SET, false, 0, 2, 0, set}, // SET 2, A
+	0b11_011_000: {SET, false, 0, 2, 0, set}, // SET 3, B
+	0b11_011_001: {SET, false, 0, 2, 0, set}, // SET 3, C
+	0b11_011_010: {SET, false, 0, 2, 0, set}, // SET 3, D
+	0b11_011_011: {SET, false, 0, 2, 0, set}, // SET 3, E
+	0b11_011_100: {SET, false, 0, 2, 0, set}, // SET 3, H
+	0b11_011_101: {SET, false, 0, 2, 0, set}, // SET 3, L
+	0b11_011_110: {SET, false, 0, 4, 0, set}, // SET 3, (HL)
+	0b11_011_111: {SET, false, 0, 2, 0, set}, // SET 3, A
+	0b11_100_000: {SET, false, 0, 2, 0, set}, // SET 4, B
+	0b11_100_001: {SET, false, 0, 2, 0, set}, // SET 4, C
+	0b11_100_010: {SET, false, 0, 2, 0, set}, // SET 4, D
+	0b11_100_011: {SET, false, 0, 2, 0, set}, // SET 4, E
+	0b11_100_100: {SET, false, 0, 2, 0, set}, // SET 4, H
+	0b11_100_101: {SET, false, 0, 2, 0, set}, // SET 4, L
+	0b11_100_110: {SET, false, 0, 4, 0, set}, // SET 4, (HL)
+	0b11_100_111: {SET, false, 0, 2, 0, set}, // SET 4, A
+	0b11_101_000: {SET, false, 0, 2, 0, set}, // SET 5, B
+	0b11_101_001: {SET, false, 0, 2, 0, set}, // SET 5, C
+	0b11_101_010: {SET, false, 0, 2, 0, set}, // SET 5, D
+	0b11_101_011: {SET, false, 0, 2, 0, set}, // SET 5, E
+	0b11_101_100: {SET, false, 0, 2, 0, set}, // SET 5, H
+	0b11_101_101: {SET, false, 0, 2, 0, set}, // SET 5, L
+	0b11_101_110: {SET, false, 0, 4, 0, set}, // SET 5, (HL)
+	0b11_101_111: {SET, false, 0, 2, 0, set}, // SET 5, A
+	0b11_110_000: {SET, false, 0, 2, 0, set}, // SET 6, B
+	0b11_110_001: {SET, false, 0, 2, 0, set}, // SET 6, C
+	0b11_110_010: {SET, false, 0, 2, 0, set}, // SET 6, D
+	0b11_110_011: {SET, false, 0, 2, 0, set}, // SET 6, E
+	0b11_110_100: {SET, false, 0, 2, 0, set}, // SET 6, H
+	0b11_110_101: {SET, false, 0, 2, 0, set}, // SET 6, L
+	0b11_110_110: {SET, false, 0, 4, 0, set}, // SET 6, (HL)
+	0b11_110_111: {SET, false, 0, 2, 0, set}, // SET 6, A
+	0b11_111_000: {SET, false, 0, 2, 0, set}, // SET 7, B
+	0b11_111_001: {SET, false, 0, 2, 0, set}, // SET 7, C
+	0b11_111_010: {SET, false, 0, 2, 0, set}, // SET 7, D
+	0b11_111_011: {SET, false, 0, 2, 0, set}, // SET 7, E
+	0b11_111_100: {SET, false, 0, 2, 0, set}, // SET 7, H
+	0b11_111_101: {SET, false, 0, 2, 0, set}, // SET 7, L
+	0b11_111_110: {SET, false, 0, 4, 0, set}, // SET 7, (HL)
+	0b11_111_111: {SET, false, 0, 2, 0, set}, // SET 7, A
 }
 
 func GetOpLookups() (map[byte]OpBytes, map[byte]OpBytes, map[OpCode]string) {
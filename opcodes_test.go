@@ -25,6 +25,29 @@ func TestOpCodeCompleteness(t *testing.T) {
 	}
 }
 
+func TestOpCodeCycles(t *testing.T) {
+	for k, v := range unprefixed {
+		if v.Cycles == 0 {
+			t.Errorf("%s: %s has no cycle count", formatInst(k, nil), opNames[v.Code])
+		}
+	}
+
+	for k, v := range cb {
+		// the (HL) forms spend extra cycles reading (and writing) memory
+		expected := uint8(2)
+		if OpCode(k).GetZ() == 6 {
+			expected = 4
+			if v.Code == BIT {
+				expected = 3
+			}
+		}
+
+		if v.Cycles != expected {
+			t.Errorf("%s: %s takes %d cycles, expected %d", formatInst(k, ptr.String("cb")), opNames[v.Code], v.Cycles, expected)
+		}
+	}
+}
+
 func formatInst(inst byte, prefix *string) (output string) {
 	i := strconv.FormatInt(int64(inst), 2)       // to binary
 	i = strings.Repeat("0", 8-len(i)) + i        // pad to 8 bits
@@ -10,36 +10,56 @@ func ld(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uin
 	gb.debugLnF("operation LD")
 
 	x, y, z := opcode.GetX(), opcode.GetY(), opcode.GetZ()
-	p, q := opcode.GetPQ()
+	p, _ := opcode.GetPQ()
 
-	if x == 0 {
-		if z == 1 && q == 0 {
+	switch x {
+	case 0:
+		switch z {
+		case 0:
+			// LD (nn), SP
+			msb, lsb := splitBytes(gb.sp)
+			gb.cpuWrite(immediate, lsb)
+			gb.cpuWrite(immediate+1, msb)
+		case 1:
+			// LD rp[p], nn
 			tableRPWrite(gb, p, immediate)
-		} else if z == 6 {
+		case 6:
 			// LD r[y], n
 			tableRWrite(gb, y, byte(immediate))
 		}
-	} else if x == 1 {
-		if z == 6 {
-			value := tableRRead(gb, z)
-
-			tableRWrite(gb, y, value)
-		}
-	} else if x == 3 {
-		if z == 0 {
-			switch y {
-			case 4:
-				gb.WriteMemory(0xFF00+immediate, gb.a)
-			}
-		} else if z == 2 {
-			switch y {
-			case 4:
-				gb.WriteMemory(0xFF00+uint16(gb.c), gb.a)
-			}
+	case 1:
+		// LD r[y], r[z]
+		tableRWrite(gb, y, tableRRead(gb, z))
+	case 3:
+		switch {
+		case z == 0 && y == 4:
+			// LD (0xFF00 + n), A
+			gb.cpuWrite(0xFF00+immediate, gb.a)
+		case z == 0 && y == 6:
+			// LD A, (0xFF00 + n)
+			gb.a = gb.cpuRead(0xFF00 + immediate)
+		case z == 0 && y == 7:
+			// LD HL, SP + d
+			gb.setHL(gb.addSP(displacement))
+		case z == 1:
+			// LD SP, HL
+			gb.sp = gb.readHL()
+		case z == 2 && y == 4:
+			// LD (0xFF00 + C), A
+			gb.cpuWrite(0xFF00+uint16(gb.c), gb.a)
+		case z == 2 && y == 5:
+			// LD (nn), A
+			gb.cpuWrite(immediate, gb.a)
+		case z == 2 && y == 6:
+			// LD A, (0xFF00 + C)
+			gb.a = gb.cpuRead(0xFF00 + uint16(gb.c))
+		case z == 2 && y == 7:
+			// LD A, (nn)
+			gb.a = gb.cpuRead(immediate)
 		}
 	}
 
-	// no flags to change
+	// no flags to change, except for LD HL, SP + d
 }
 
 // ldid covers both LDD and LDI
@@ -59,9 +79,9 @@ func ldid(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate u
 	}
 
 	if q == 1 {
-		gb.a = gb.ReadMemory(memLoc)
+		gb.a = gb.cpuRead(memLoc)
 	} else {
-		gb.WriteMemory(memLoc, gb.a)
+		gb.cpuWrite(memLoc, gb.a)
 	}
 
 	if p == 2 {
@@ -77,91 +97,163 @@ func inc(gb *GameBoy, prefix uint8, opcode OpCode, displacement uint8, immediate
 	gb.debugLnF("operation INC")
 
 	y, z := opcode.GetY(), opcode.GetZ()
-	var oldVal uint8
-
-	if z == 4 {
-		oldVal = tableRRead(gb, y)
-		tableRWrite(gb, y, oldVal+1)
-	}
+	p, _ := opcode.GetPQ()
 
-	if oldVal+1 == 0 {
-		gb.f |= MaskZeroFlag
-	} else {
-		gb.f &= ^MaskZeroFlag
+	if z == 3 {
+		// INC rp[p], 16 bit increments don't touch the flags
+		tableRPWrite(gb, p, tableRPRead(gb, p)+1)
+		return
 	}
 
-	gb.f &= ^MaskSubtractionFlag
+	// INC r[y]
+	oldVal := tableRRead(gb, y)
+	tableRWrite(gb, y, oldVal+1)
 
-	if oldVal&0xF+(0x1&0xF) == 0x10 {
-		gb.f |= MaskHalfCarryFlag
-	} else {
-		gb.f &= ^MaskHalfCarryFlag
-	}
+	gb.setFlag(MaskZeroFlag, oldVal+1 == 0)
+	gb.setFlag(MaskSubtractionFlag, false)
+	gb.setFlag(MaskHalfCarryFlag, oldVal&0xF == 0xF)
 
+	// carry is unaffected
 }
 
 func dec(gb *GameBoy, prefix uint8, opcode OpCode, displacement uint8, immediate uint16) {
 	gb.debugLnF("operation DEC")
 
-	z := opcode.GetZ()
+	y, z := opcode.GetY(), opcode.GetZ()
 	p, _ := opcode.GetPQ()
-	var old16 uint16
-	var old8 uint8
-	is8 := false
 
 	if z == 3 {
-		old16 = tableRPRead(gb, p)
-		tableRPWrite(gb, p, old16-1)
-	} else if z == 5 {
-		is8 = true
-		old8 = tableRRead(gb, p)
-		tableRWrite(gb, p, old8-1)
+		// DEC rp[p], 16 bit decrements don't touch the flags
+		tableRPWrite(gb, p, tableRPRead(gb, p)-1)
+		return
 	}
 
-	gb.f |= MaskSubtractionFlag
+	// DEC r[y]
+	oldVal := tableRRead(gb, y)
+	tableRWrite(gb, y, oldVal-1)
 
-	if is8 {
-		if old8-1 == 0 {
-			gb.f |= MaskZeroFlag
-		} else {
-			gb.f &= ^MaskZeroFlag
-		}
+	gb.setFlag(MaskZeroFlag, oldVal-1 == 0)
+	gb.setFlag(MaskSubtractionFlag, true)
+	gb.setFlag(MaskHalfCarryFlag, oldVal&0xF == 0)
 
-		if old8&0xF-(0x1&0xF) == 0x10 {
-			gb.f |= MaskHalfCarryFlag
-		} else {
-			gb.f &= ^MaskHalfCarryFlag
-		}
-	} else {
-		if old16-1 == 0 {
-			gb.f |= MaskZeroFlag
-		} else {
-			gb.f &= ^MaskZeroFlag
-		}
+	// carry is unaffected
+}
 
-		if old16&0xFFF-(0x1&0xFFF) == 0x1000 {
-			gb.f |= MaskHalfCarryFlag
-		} else {
-			gb.f &= ^MaskHalfCarryFlag
-		}
+// add covers the 16 bit additions, the 8 bit ones are part of alu
+func add(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation ADD")
+
+	x := opcode.GetX()
+	p, _ := opcode.GetPQ()
+
+	if x == 3 {
+		// ADD SP, d
+		gb.sp = gb.addSP(displacement)
+		return
 	}
+
+	// ADD HL, rp[p]
+	hl := gb.readHL()
+	value := tableRPRead(gb, p)
+	sum := uint32(hl) + uint32(value)
+
+	gb.setHL(uint16(sum))
+
+	// zero is unaffected, the carries come from the upper byte
+	gb.setFlag(MaskSubtractionFlag, false)
+	gb.setFlag(MaskHalfCarryFlag, hl&0xFFF+value&0xFFF > 0xFFF)
+	gb.setFlag(MaskCarryFlag, sum > 0xFFFF)
 }
 
-func xor(gb *GameBoy, prefix uint8, opcode OpCode, displacement uint8, immediate uint16) {
-	gb.debugLnF("operation XOR")
+// addSP adds a signed displacement to SP without storing it, for ADD SP, d and
+// LD HL, SP + d. Both set H and C from an unsigned addition of the low bytes.
+func (gb *GameBoy) addSP(displacement uint8) (result uint16) {
+	result = uint16(int32(gb.sp) + int32(int8(displacement)))
 
-	z := opcode.GetZ()
+	gb.f = 0
+	gb.setFlag(MaskHalfCarryFlag, gb.sp&0xF+uint16(displacement&0xF) > 0xF)
+	gb.setFlag(MaskCarryFlag, gb.sp&0xFF+uint16(displacement) > 0xFF)
 
-	value := tableRRead(gb, z)
+	return result
+}
 
-	gb.a ^= value
+// alu covers ADD, ADC, SUB, SBC, AND, XOR, OR and CP, against either r[z] or an immediate
+func alu(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation ALU")
 
-	// set zero flag
-	if gb.a == 0 {
-		gb.f |= MaskZeroFlag
+	x, y, z := opcode.GetX(), opcode.GetY(), opcode.GetZ()
+
+	var value uint8
+	if x == 3 {
+		// ALU A, n
+		value = uint8(immediate)
 	} else {
-		gb.f &= ^MaskZeroFlag
+		// ALU A, r[z]
+		value = tableRRead(gb, z)
+	}
+
+	// table alu from https://gb-archive.github.io/salvage/decoding_gbz80_opcodes/Decoding%20Gamboy%20Z80%20Opcodes.html
+	switch y {
+	case 0: // ADD A, v
+		gb.a = gb.add8(value, false)
+	case 1: // ADC A, v
+		gb.a = gb.add8(value, gb.flag(MaskCarryFlag))
+	case 2: // SUB v
+		gb.a = gb.sub8(value, false)
+	case 3: // SBC A, v
+		gb.a = gb.sub8(value, gb.flag(MaskCarryFlag))
+	case 4: // AND v
+		gb.a &= value
+		gb.f = MaskHalfCarryFlag
+	case 5: // XOR v
+		gb.a ^= value
+		gb.f = 0
+	case 6: // OR v
+		gb.a |= value
+		gb.f = 0
+	case 7: // CP v, a subtraction that only keeps the flags
+		gb.sub8(value, false)
+	}
+
+	if y >= 4 && y <= 6 {
+		gb.setFlag(MaskZeroFlag, gb.a == 0)
+	}
+}
+
+// add8 adds value and the carry to A, returning the result and setting all flags
+func (gb *GameBoy) add8(value uint8, carry bool) (result uint8) {
+	var c uint16
+	if carry {
+		c = 1
+	}
+
+	sum := uint16(gb.a) + uint16(value) + c
+	result = uint8(sum)
+
+	gb.setFlag(MaskZeroFlag, result == 0)
+	gb.setFlag(MaskSubtractionFlag, false)
+	gb.setFlag(MaskHalfCarryFlag, uint16(gb.a&0xF)+uint16(value&0xF)+c > 0xF)
+	gb.setFlag(MaskCarryFlag, sum > 0xFF)
+
+	return result
+}
+
+// sub8 subtracts value and the carry (borrow) from A, returning the result and setting all flags
+func (gb *GameBoy) sub8(value uint8, carry bool) (result uint8) {
+	var c int
+	if carry {
+		c = 1
 	}
+
+	diff := int(gb.a) - int(value) - c
+	result = uint8(diff)
+
+	gb.setFlag(MaskZeroFlag, result == 0)
+	gb.setFlag(MaskSubtractionFlag, true)
+	gb.setFlag(MaskHalfCarryFlag, int(gb.a&0xF)-int(value&0xF)-c < 0)
+	gb.setFlag(MaskCarryFlag, diff < 0)
+
+	return result
 }
 
 func bit(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
@@ -182,32 +274,52 @@ func bit(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate ui
 	gb.f |= MaskHalfCarryFlag
 }
 
+func res(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation RES")
+
+	y, z := opcode.GetY(), opcode.GetZ()
+
+	tableRWrite(gb, z, tableRRead(gb, z)&^(1<<y))
+
+	// no flags to change
+}
+
+func set(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation SET")
+
+	y, z := opcode.GetY(), opcode.GetZ()
+
+	tableRWrite(gb, z, tableRRead(gb, z)|1<<y)
+
+	// no flags to change
+}
+
 func jr(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
 	gb.debugLnF("operation JR")
 
 	y := opcode.GetY()
 
-	var jump bool
-
-	if y == 3 {
-		jump = true
-	} else {
-		// y = 4..7
-		switch y {
-		case 4: // NZ
-			jump = gb.f&MaskZeroFlag == 0
-		case 5: // Z
-			jump = gb.f&MaskZeroFlag != 0
-		case 6: // NC
-			jump = gb.f&MaskCarryFlag == 0
-		case 7: // C
-			jump = gb.f&MaskCarryFlag != 0
-		}
+	// JR d when y is 3, otherwise JR cc[y-4], d
+	if y == 3 || gb.condition(y-4) {
+		signedEnlargedDisplacement := int16(int8(displacement))
+		gb.jump(uint16(int16(gb.pc) + signedEnlargedDisplacement))
 	}
+}
 
-	if jump {
-		signedEnlargedDisplacement := int16(int8(displacement))
-		gb.pc = uint16(int16(gb.pc) + signedEnlargedDisplacement)
+func jp(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation JP")
+
+	y, z := opcode.GetY(), opcode.GetZ()
+
+	switch z {
+	case 1: // JP HL
+		gb.jump(gb.readHL())
+	case 2: // JP cc[y], nn
+		if gb.condition(y) {
+			gb.jump(immediate)
+		}
+	case 3: // JP nn
+		gb.jump(immediate)
 	}
 }
 
@@ -218,6 +330,8 @@ func push(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate u
 
 	rp2 := tableRP2Read(gb, p)
 
+	// an internal delay before the writes
+	gb.tick()
 	gb.PushStack(rp2)
 }
 
@@ -234,46 +348,211 @@ func pop(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate ui
 func call(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
 	gb.debugLnF("operation CALL")
 
-	// the call instruction is 3 bytes long and the PC still points to it as the
-	// current instruction
-	nextPC := gb.pc + 3
+	y, z := opcode.GetY(), opcode.GetZ()
 
-	gb.PushStack(nextPC)
-	gb.pc = immediate - 3 // -3 because the PC is incremented by 3 after the instruction is executed
+	// CALL nn when z is 5, otherwise CALL cc[y], nn
+	if z == 5 || gb.condition(y) {
+		gb.tick()
+		gb.PushStack(gb.pc)
+		gb.jump(immediate)
+	}
 }
 
-func rl(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
-	gb.debugLnF("operation RL")
+func ret(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation RET")
 
-	z := opcode.GetZ()
+	y, z := opcode.GetY(), opcode.GetZ()
 
-	// var bit bool
-	r := tableRRead(gb, z)
-	bit := r&1<<7 != 0
-	tableRWrite(gb, z, r<<1)
+	if z == 0 {
+		// checking the condition takes an M-cycle of its own
+		gb.tick()
+	}
 
-	if bit {
-		// set carry
-		gb.f |= MaskCarryFlag
-	} else {
-		// clear carry
-		gb.f &= ^MaskCarryFlag
+	// RET when z is 1, otherwise RET cc[y]
+	if z == 1 || gb.condition(y) {
+		gb.jump(gb.PopStack())
 	}
 }
 
-func rla(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
-	gb.debugLnF("operation RLA")
+func reti(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation RETI")
+
+	gb.jump(gb.PopStack())
+
+	// unlike EI there's no delay
+	gb.ime = true
+}
+
+func rst(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation RST")
+
+	y := opcode.GetY()
+
+	gb.tick()
+	gb.PushStack(gb.pc)
+	gb.jump(uint16(y) * 8)
+}
+
+// rot covers the CB prefixed rotates and shifts
+func rot(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation ROT")
+
+	y, z := opcode.GetY(), opcode.GetZ()
+
+	result := gb.rotate(y, tableRRead(gb, z))
+	tableRWrite(gb, z, result)
+
+	gb.setFlag(MaskZeroFlag, result == 0)
+}
+
+// rotate applies rotation y from table rot to value, setting the carry flag and
+// clearing N and H, Z is left to the caller
+func (gb *GameBoy) rotate(y uint8, value uint8) (result uint8) {
+	var carryIn uint8
+	if gb.flag(MaskCarryFlag) {
+		carryIn = 1
+	}
+
+	var carry bool
+
+	// table rot from https://gb-archive.github.io/salvage/decoding_gbz80_opcodes/Decoding%20Gamboy%20Z80%20Opcodes.html
+	switch y {
+	case 0: // RLC, bit 7 goes to both the carry and bit 0
+		carry = value&0x80 != 0
+		result = value<<1 | value>>7
+	case 1: // RRC, bit 0 goes to both the carry and bit 7
+		carry = value&0x01 != 0
+		result = value>>1 | value<<7
+	case 2: // RL, a 9 bit rotate through the carry
+		carry = value&0x80 != 0
+		result = value<<1 | carryIn
+	case 3: // RR, a 9 bit rotate through the carry
+		carry = value&0x01 != 0
+		result = value>>1 | carryIn<<7
+	case 4: // SLA
+		carry = value&0x80 != 0
+		result = value << 1
+	case 5: // SRA, bit 7 stays put
+		carry = value&0x01 != 0
+		result = value>>1 | value&0x80
+	case 6: // SWAP
+		result = value<<4 | value>>4
+	case 7: // SRL
+		carry = value&0x01 != 0
+		result = value >> 1
+	}
+
+	gb.f = 0
+	gb.setFlag(MaskCarryFlag, carry)
+
+	return result
+}
+
+// rota covers RLCA, RRCA, RLA and RRA, the accumulator only versions of the
+// first four CB rotates. Unlike those, Z is always cleared.
+func rota(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation ROTA")
 
-	bit := (gb.a & 0x80) != 0
-	gb.a <<= 1
+	gb.a = gb.rotate(opcode.GetY(), gb.a)
+}
+
+// daa adjusts A back into binary coded decimal after an addition or a
+// subtraction of two BCD values, using N, H and C to know which it was
+func daa(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation DAA")
+
+	subtraction := gb.flag(MaskSubtractionFlag)
+	carry := gb.flag(MaskCarryFlag)
+
+	var adjust uint8
+	if gb.flag(MaskHalfCarryFlag) || (!subtraction && gb.a&0x0F > 0x09) {
+		adjust |= 0x06
+	}
 
-	if bit {
-		// set carry
-		gb.f |= MaskCarryFlag
+	if carry || (!subtraction && gb.a > 0x99) {
+		adjust |= 0x60
+		carry = true
+	}
+
+	if subtraction {
+		gb.a -= adjust
 	} else {
-		// clear carry
-		gb.f &= ^MaskCarryFlag
+		gb.a += adjust
 	}
+
+	// N is unaffected
+	gb.setFlag(MaskZeroFlag, gb.a == 0)
+	gb.setFlag(MaskHalfCarryFlag, false)
+	gb.setFlag(MaskCarryFlag, carry)
+}
+
+func cpl(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation CPL")
+
+	gb.a = ^gb.a
+
+	// zero and carry are unaffected
+	gb.setFlag(MaskSubtractionFlag, true)
+	gb.setFlag(MaskHalfCarryFlag, true)
+}
+
+func scf(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation SCF")
+
+	// zero is unaffected
+	gb.setFlag(MaskSubtractionFlag, false)
+	gb.setFlag(MaskHalfCarryFlag, false)
+	gb.setFlag(MaskCarryFlag, true)
+}
+
+func ccf(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation CCF")
+
+	// zero is unaffected
+	gb.setFlag(MaskSubtractionFlag, false)
+	gb.setFlag(MaskHalfCarryFlag, false)
+	gb.setFlag(MaskCarryFlag, !gb.flag(MaskCarryFlag))
+}
+
+func nop(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation NOP")
+}
+
+// halt suspends the CPU until an interrupt is pending. If one already is but
+// IME is off, the CPU doesn't halt and instead trips over the HALT bug.
+func halt(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation HALT")
+
+	if !gb.ime && gb.pendingInterrupts() != 0 {
+		gb.haltBug = true
+		return
+	}
+
+	gb.halted = true
+}
+
+// stop suspends the CPU and the LCD until a button is pressed. The byte after
+// STOP is skipped, see the opcode table.
+func stop(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation STOP")
+
+	gb.stopped = true
+
+	// the divider is reset like a write to DIV
+	gb.setTimerCounter(0)
+}
+
+func di(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation DI")
+
+	gb.ime = false
+	gb.eiPending = false
+}
+
+func ei(gb *GameBoy, ext uint8, opcode OpCode, displacement uint8, immediate uint16) {
+	gb.debugLnF("operation EI")
+
+	gb.eiPending = true
 }
 
 func tableRRead(gb *GameBoy, z uint8) (value uint8) {
@@ -292,7 +571,7 @@ func tableRRead(gb *GameBoy, z uint8) (value uint8) {
 	case 5:
 		return gb.l
 	case 6:
-		return gb.ReadMemory(gb.readHL())
+		return gb.cpuRead(gb.readHL())
 	case 7:
 		return gb.a
 	}
@@ -316,7 +595,7 @@ func tableRWrite(gb *GameBoy, z uint8, value uint8) {
 	case 5:
 		gb.l = value
 	case 6:
-		gb.WriteMemory(gb.readHL(), value)
+		gb.cpuWrite(gb.readHL(), value)
 	case 7:
 		gb.a = value
 	default:
@@ -0,0 +1,511 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCPU returns a GameBoy about to execute program from the start of WRAM
+func newTestCPU(program ...uint8) (gb *GameBoy) {
+	gb = &GameBoy{}
+	copy(gb.wram[:], program)
+	gb.pc = 0xC000
+	gb.sp = 0xFFFE
+
+	return gb
+}
+
+// flags builds a flags register from Z, N, H and C
+func flags(z, n, h, c bool) (f uint8) {
+	for _, flag := range []struct {
+		set  bool
+		mask uint8
+	}{
+		{z, MaskZeroFlag},
+		{n, MaskSubtractionFlag},
+		{h, MaskHalfCarryFlag},
+		{c, MaskCarryFlag},
+	} {
+		if flag.set {
+			f |= flag.mask
+		}
+	}
+
+	return f
+}
+
+func TestALUImmediate(t *testing.T) {
+	cases := []struct {
+		name    string
+		opcode  uint8
+		a       uint8
+		n       uint8
+		f       uint8
+		resultA uint8
+		resultF uint8
+	}{
+		{"ADD", 0xC6, 0x3A, 0xC6, 0, 0x00, flags(true, false, true, true)},
+		{"ADD half carry", 0xC6, 0x0F, 0x01, 0, 0x10, flags(false, false, true, false)},
+		{"ADD ignores carry", 0xC6, 0x01, 0x01, MaskCarryFlag, 0x02, 0},
+		{"ADC", 0xCE, 0xE1, 0x1E, MaskCarryFlag, 0x00, flags(true, false, true, true)},
+		{"ADC no carry", 0xCE, 0xE1, 0x0E, 0, 0xEF, 0},
+		{"SUB", 0xD6, 0x3E, 0x3E, 0, 0x00, flags(true, true, false, false)},
+		{"SUB borrow", 0xD6, 0x3E, 0x40, 0, 0xFE, flags(false, true, false, true)},
+		{"SUB half borrow", 0xD6, 0x3E, 0x0F, 0, 0x2F, flags(false, true, true, false)},
+		{"SBC", 0xDE, 0x3B, 0x2A, MaskCarryFlag, 0x10, flags(false, true, false, false)},
+		{"SBC borrow", 0xDE, 0x3B, 0x4F, MaskCarryFlag, 0xEB, flags(false, true, true, true)},
+		{"SBC carry into zero", 0xDE, 0x00, 0xFF, MaskCarryFlag, 0x00, flags(true, true, true, true)},
+		{"AND", 0xE6, 0x5A, 0x3F, MaskCarryFlag, 0x1A, flags(false, false, true, false)},
+		{"AND zero", 0xE6, 0x5A, 0x00, 0, 0x00, flags(true, false, true, false)},
+		{"XOR", 0xEE, 0xFF, 0x0F, 0xF0, 0xF0, 0},
+		{"XOR zero", 0xEE, 0xFF, 0xFF, 0, 0x00, flags(true, false, false, false)},
+		{"OR", 0xF6, 0x5A, 0x03, 0xF0, 0x5B, 0},
+		{"OR zero", 0xF6, 0x00, 0x00, 0, 0x00, flags(true, false, false, false)},
+		{"CP equal", 0xFE, 0x3C, 0x3C, 0, 0x3C, flags(true, true, false, false)},
+		{"CP less", 0xFE, 0x3C, 0x40, 0, 0x3C, flags(false, true, false, true)},
+		{"CP half", 0xFE, 0x3C, 0x2F, 0, 0x3C, flags(false, true, true, false)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gb := newTestCPU(tc.opcode, tc.n)
+			gb.a, gb.f = tc.a, tc.f
+
+			gb.RunInstruction()
+
+			assert.Equal(t, tc.resultA, gb.a, "A")
+			assert.Equal(t, tc.resultF, gb.f, "F: %08b", gb.f)
+			assert.Equal(t, uint16(0xC002), gb.pc)
+		})
+	}
+}
+
+func TestALURegisters(t *testing.T) {
+	// ADD A, B; SUB (HL); XOR A
+	gb := newTestCPU(0x80, 0x96, 0xAF)
+	gb.a, gb.b = 0x10, 0x20
+	gb.setHL(0xC100)
+	gb.wram[0x0100] = 0x05
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x30), gb.a)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x2B), gb.a)
+	assert.Equal(t, flags(false, true, true, false), gb.f)
+
+	gb.f = 0xF0
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x00), gb.a)
+	assert.Equal(t, flags(true, false, false, false), gb.f)
+}
+
+func TestCBRotatesAndShifts(t *testing.T) {
+	cases := []struct {
+		name    string
+		opcode  uint8 // operates on B
+		b       uint8
+		f       uint8
+		resultB uint8
+		resultF uint8
+	}{
+		{"RLC", 0x00, 0x85, 0, 0x0B, flags(false, false, false, true)},
+		{"RLC zero", 0x00, 0x00, 0xF0, 0x00, flags(true, false, false, false)},
+		{"RRC", 0x08, 0x01, 0, 0x80, flags(false, false, false, true)},
+		{"RL carry in", 0x10, 0x80, MaskCarryFlag, 0x01, flags(false, false, false, true)},
+		{"RL carry out only", 0x10, 0x80, 0, 0x00, flags(true, false, false, true)},
+		{"RR carry in", 0x18, 0x01, MaskCarryFlag, 0x80, flags(false, false, false, true)},
+		{"SLA", 0x20, 0xFF, 0, 0xFE, flags(false, false, false, true)},
+		{"SRA keeps bit 7", 0x28, 0x8A, 0, 0xC5, 0},
+		{"SWAP", 0x30, 0xF1, MaskCarryFlag, 0x1F, 0},
+		{"SWAP zero", 0x30, 0x00, 0, 0x00, flags(true, false, false, false)},
+		{"SRL", 0x38, 0x01, 0, 0x00, flags(true, false, false, true)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gb := newTestCPU(0xCB, tc.opcode)
+			gb.b, gb.f = tc.b, tc.f
+
+			gb.RunInstruction()
+
+			assert.Equal(t, tc.resultB, gb.b, "B")
+			assert.Equal(t, tc.resultF, gb.f, "F: %08b", gb.f)
+		})
+	}
+}
+
+func TestCBMemory(t *testing.T) {
+	// SET 7, (HL); RES 0, (HL); SWAP (HL); BIT 3, (HL)
+	gb := newTestCPU(0xCB, 0xFE, 0xCB, 0x86, 0xCB, 0x36, 0xCB, 0x5E)
+	gb.setHL(0xC100)
+	gb.wram[0x0100] = 0x01
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x81), gb.wram[0x0100])
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x80), gb.wram[0x0100])
+
+	ticks := gb.tickCount
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x08), gb.wram[0x0100])
+	assert.Equal(t, uint64(16), gb.tickCount-ticks)
+
+	gb.RunInstruction()
+	assert.Equal(t, flags(false, false, true, false), gb.f)
+}
+
+func TestJumps(t *testing.T) {
+	cases := []struct {
+		name    string
+		program []uint8
+		f       uint8
+		pc      uint16
+		cycles  uint64
+	}{
+		{"JR back", []uint8{0x18, 0xFE}, 0, 0xC000, 3},
+		{"JR NZ taken", []uint8{0x20, 0x10}, 0, 0xC012, 3},
+		{"JR NZ not taken", []uint8{0x20, 0x10}, MaskZeroFlag, 0xC002, 2},
+		{"JP nn", []uint8{0xC3, 0x34, 0x12}, 0, 0x1234, 4},
+		{"JP C taken", []uint8{0xDA, 0x34, 0x12}, MaskCarryFlag, 0x1234, 4},
+		{"JP C not taken", []uint8{0xDA, 0x34, 0x12}, 0, 0xC003, 3},
+		{"JP HL", []uint8{0xE9}, 0, 0xD000, 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gb := newTestCPU(tc.program...)
+			gb.f = tc.f
+			gb.setHL(0xD000)
+
+			gb.RunInstruction()
+
+			assert.Equal(t, tc.pc, gb.pc, "PC: %.4X", gb.pc)
+			assert.Equal(t, tc.cycles*4, gb.tickCount)
+		})
+	}
+}
+
+func TestCallAndReturn(t *testing.T) {
+	gb := newTestCPU(
+		0xCD, 0x10, 0xC0, // 0xC000: CALL 0xC010
+		0xC4, 0x10, 0xC0, // 0xC003: CALL NZ, 0xC010
+	)
+	copy(gb.wram[0x0010:], []uint8{
+		0xC8, // 0xC010: RET Z
+		0xC9, // 0xC011: RET
+	})
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC010), gb.pc)
+	assert.Equal(t, uint16(0xFFFC), gb.sp)
+	assert.Equal(t, uint16(0xC003), gb.readMemory16(gb.sp))
+	assert.Equal(t, uint64(6*4), gb.tickCount)
+
+	// not taken
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC011), gb.pc)
+	assert.Equal(t, uint64(8*4), gb.tickCount)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC003), gb.pc)
+	assert.Equal(t, uint16(0xFFFE), gb.sp)
+
+	// not taken either
+	gb.f = MaskZeroFlag
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC006), gb.pc)
+	assert.Equal(t, uint16(0xFFFE), gb.sp)
+
+	gb.pc = 0xC010
+	gb.PushStack(0xC123)
+	ticks := gb.tickCount
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC123), gb.pc)
+	assert.Equal(t, uint64(5*4), gb.tickCount-ticks)
+}
+
+func TestRSTAndRETI(t *testing.T) {
+	gb := newTestCPU(0xEF) // RST 28H
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0x0028), gb.pc)
+	assert.Equal(t, uint16(0xC001), gb.readMemory16(gb.sp))
+
+	gb.wram[0x0100] = 0xD9 // RETI
+	gb.pc = 0xC100
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC001), gb.pc)
+	assert.True(t, gb.ime)
+}
+
+func Test16BitIncDec(t *testing.T) {
+	gb := newTestCPU(
+		0x03, // INC BC
+		0x1B, // DEC DE
+		0x05, // DEC B
+		0x0C, // INC C
+	)
+	gb.setBC(0x00FF)
+	gb.f = flags(true, true, true, true)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0x0100), gb.readBC())
+	assert.Equal(t, flags(true, true, true, true), gb.f, "INC rp leaves flags alone")
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xFFFF), gb.readDE())
+	assert.Equal(t, flags(true, true, true, true), gb.f, "DEC rp leaves flags alone")
+	assert.Equal(t, uint64(4*4), gb.tickCount)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x00), gb.b)
+	assert.Equal(t, flags(true, true, false, true), gb.f)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x01), gb.c)
+	assert.Equal(t, flags(false, false, false, true), gb.f)
+}
+
+func TestAddHL(t *testing.T) {
+	cases := []struct {
+		name    string
+		hl      uint16
+		bc      uint16
+		f       uint8
+		resultF uint8
+	}{
+		{"no carries", 0x1234, 0x0101, MaskZeroFlag | MaskSubtractionFlag, MaskZeroFlag},
+		{"half carry", 0x0FFF, 0x0001, 0, MaskHalfCarryFlag},
+		{"carry", 0x8000, 0x8000, 0, MaskCarryFlag},
+		{"both", 0xFFFF, 0x0001, 0, MaskHalfCarryFlag | MaskCarryFlag},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gb := newTestCPU(0x09) // ADD HL, BC
+			gb.setHL(tc.hl)
+			gb.setBC(tc.bc)
+			gb.f = tc.f
+
+			gb.RunInstruction()
+
+			assert.Equal(t, tc.hl+tc.bc, gb.readHL())
+			assert.Equal(t, tc.resultF, gb.f, "F: %.8b", gb.f)
+			assert.Equal(t, uint64(2*4), gb.tickCount)
+		})
+	}
+}
+
+func TestStackPointerArithmetic(t *testing.T) {
+	cases := []struct {
+		name    string
+		opcode  uint8
+		sp      uint16
+		d       uint8
+		result  uint16
+		resultF uint8
+	}{
+		{"ADD SP positive", 0xE8, 0xFFF8, 0x02, 0xFFFA, 0},
+		{"ADD SP negative", 0xE8, 0x0005, 0xFE, 0x0003, MaskHalfCarryFlag | MaskCarryFlag},
+		{"ADD SP low byte carry", 0xE8, 0x00FF, 0x01, 0x0100, MaskHalfCarryFlag | MaskCarryFlag},
+		{"ADD SP never zero", 0xE8, 0x0000, 0x00, 0x0000, 0},
+		{"LD HL, SP + d", 0xF8, 0xFFF8, 0x08, 0x0000, MaskHalfCarryFlag | MaskCarryFlag},
+		{"LD HL, SP - d", 0xF8, 0xD000, 0xFF, 0xCFFF, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gb := newTestCPU(tc.opcode, tc.d)
+			gb.sp = tc.sp
+			gb.f = MaskZeroFlag | MaskSubtractionFlag
+
+			gb.RunInstruction()
+
+			if tc.opcode == 0xE8 {
+				assert.Equal(t, tc.result, gb.sp, "SP: %.4X", gb.sp)
+			} else {
+				assert.Equal(t, tc.result, gb.readHL(), "HL: %.4X", gb.readHL())
+				assert.Equal(t, tc.sp, gb.sp)
+			}
+			assert.Equal(t, tc.resultF, gb.f, "F: %.8b", gb.f)
+		})
+	}
+}
+
+func TestLoads(t *testing.T) {
+	gb := newTestCPU(
+		0x01, 0x34, 0x12, // LD BC, 0x1234
+		0x50,             // LD D, B
+		0x71,             // LD (HL), C
+		0x7E,             // LD A, (HL)
+		0x08, 0x10, 0xC1, // LD (0xC110), SP
+		0xEA, 0x20, 0xC1, // LD (0xC120), A
+		0xFA, 0x10, 0xC1, // LD A, (0xC110)
+		0xF0, 0x80, // LD A, (0xFF80)
+		0xF2, // LD A, (0xFF00 + C)
+		0xF9, // LD SP, HL
+	)
+	gb.setHL(0xC100)
+	gb.hram[0] = 0x99
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0x1234), gb.readBC())
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x12), gb.d)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x34), gb.wram[0x0100])
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x34), gb.a)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xFFFE), gb.readMemory16(0xC110))
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x34), gb.wram[0x0120])
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0xFE), gb.a)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x99), gb.a)
+
+	gb.c = 0x81
+	gb.hram[1] = 0x77
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x77), gb.a)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC100), gb.sp)
+}
+
+func TestDAA(t *testing.T) {
+	cases := []struct {
+		name    string
+		program []uint8
+		a       uint8
+		resultA uint8
+		resultF uint8
+	}{
+		{"ADD no adjust", []uint8{0xC6, 0x12, 0x27}, 0x34, 0x46, 0},
+		{"ADD low digit", []uint8{0xC6, 0x08, 0x27}, 0x15, 0x23, 0},
+		{"ADD half carry", []uint8{0xC6, 0x09, 0x27}, 0x19, 0x28, 0},
+		{"ADD high digit", []uint8{0xC6, 0x50, 0x27}, 0x60, 0x10, MaskCarryFlag},
+		{"ADD to 100", []uint8{0xC6, 0x01, 0x27}, 0x99, 0x00, flags(true, false, false, true)},
+		{"ADD carry", []uint8{0xC6, 0x90, 0x27}, 0x90, 0x80, MaskCarryFlag},
+		{"SUB no adjust", []uint8{0xD6, 0x12, 0x27}, 0x34, 0x22, MaskSubtractionFlag},
+		{"SUB half borrow", []uint8{0xD6, 0x08, 0x27}, 0x15, 0x07, MaskSubtractionFlag},
+		{"SUB borrow", []uint8{0xD6, 0x01, 0x27}, 0x00, 0x99, flags(false, true, false, true)},
+		{"SUB to zero", []uint8{0xD6, 0x42, 0x27}, 0x42, 0x00, flags(true, true, false, false)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gb := newTestCPU(tc.program...)
+			gb.a = tc.a
+
+			gb.RunInstruction()
+			gb.RunInstruction()
+
+			assert.Equal(t, tc.resultA, gb.a, "A: %.2X", gb.a)
+			assert.Equal(t, tc.resultF, gb.f, "F: %.8b", gb.f)
+		})
+	}
+}
+
+func TestAccumulatorRotates(t *testing.T) {
+	cases := []struct {
+		name    string
+		opcode  uint8
+		a       uint8
+		f       uint8
+		resultA uint8
+		resultF uint8
+	}{
+		{"RLCA", 0x07, 0x85, MaskZeroFlag, 0x0B, MaskCarryFlag},
+		{"RLCA zero", 0x07, 0x00, 0, 0x00, 0},
+		{"RRCA", 0x0F, 0x01, 0, 0x80, MaskCarryFlag},
+		{"RLA", 0x17, 0x80, 0, 0x00, MaskCarryFlag},
+		{"RLA carry in", 0x17, 0x40, MaskCarryFlag, 0x81, 0},
+		{"RRA", 0x1F, 0x01, flags(true, true, true, false), 0x00, MaskCarryFlag},
+		{"RRA carry in", 0x1F, 0x02, MaskCarryFlag, 0x81, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gb := newTestCPU(tc.opcode)
+			gb.a = tc.a
+			gb.f = tc.f
+
+			gb.RunInstruction()
+
+			assert.Equal(t, tc.resultA, gb.a, "A: %.2X", gb.a)
+			assert.Equal(t, tc.resultF, gb.f, "F: %.8b", gb.f)
+		})
+	}
+}
+
+func TestFlagOps(t *testing.T) {
+	gb := newTestCPU(
+		0x2F, // CPL
+		0x37, // SCF
+		0x3F, // CCF
+		0x3F, // CCF
+	)
+	gb.a = 0x35
+	gb.f = MaskZeroFlag
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0xCA), gb.a)
+	assert.Equal(t, flags(true, true, true, false), gb.f)
+
+	gb.RunInstruction()
+	assert.Equal(t, flags(true, false, false, true), gb.f)
+
+	gb.RunInstruction()
+	assert.Equal(t, flags(true, false, false, false), gb.f)
+
+	gb.RunInstruction()
+	assert.Equal(t, flags(true, false, false, true), gb.f)
+}
+
+func TestSuspend(t *testing.T) {
+	gb := newTestCPU(
+		0xFB,       // EI
+		0xF3,       // DI
+		0x10, 0x00, // STOP
+		0x76, // HALT
+	)
+
+	// EI is delayed by an instruction, which DI cancels
+	gb.RunInstruction()
+	assert.False(t, gb.ime)
+
+	gb.RunInstruction()
+	assert.False(t, gb.ime)
+	assert.False(t, gb.eiPending)
+
+	gb.RunInstruction()
+	assert.True(t, gb.stopped)
+	assert.Equal(t, uint16(0xC004), gb.pc, "STOP skips the following byte")
+
+	// nothing is fetched while stopped
+	ticks := gb.tickCount
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC004), gb.pc)
+	assert.Equal(t, uint64(4), gb.tickCount-ticks)
+
+	gb.stopped = false
+	gb.RunInstruction()
+	assert.True(t, gb.halted)
+	assert.Equal(t, uint16(0xC005), gb.pc)
+
+	gb.RunInstruction()
+	assert.Equal(t, uint16(0xC005), gb.pc)
+}
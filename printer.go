@@ -0,0 +1,283 @@
+package goboy
+
+import (
+	"image"
+)
+
+// printer commands
+const (
+	printerInit   uint8 = 0x01 // clear the image buffer
+	printerPrint  uint8 = 0x02 // print the buffer
+	printerData   uint8 = 0x04 // add image data to the buffer, an empty packet ends it
+	printerStatus uint8 = 0x0F // just report the status
+)
+
+// printer status bits
+const (
+	printerChecksumError uint8 = 1 << 0
+	printerBusy          uint8 = 1 << 1 // printing
+	printerFull          uint8 = 1 << 2 // the image data is complete and ready to print
+	printerUnprocessed   uint8 = 1 << 3 // there is image data that hasn't been printed
+	printerPacketError   uint8 = 1 << 4
+)
+
+const (
+	printerBufferSize = 0x2000  // bytes of image data the printer holds
+	printerTileRow    = 20 * 16 // bytes in a row of tiles across the paper
+	printerFeedRows   = 16      // pixel rows a margin line feed leaves blank, as tall as a DATA packet
+	printerBusyPolls  = 4       // STATUS packets a print reports busy for
+)
+
+// packet positions, see Printer.Transfer
+const (
+	packetMagic1 = iota
+	packetMagic2
+	packetCommand
+	packetCompression
+	packetLengthLow
+	packetLengthHigh
+	packetData
+	packetChecksumLow
+	packetChecksumHigh
+	packetAck
+	packetStatus
+)
+
+// Printer is a Game Boy Printer to plug into the serial port. Every completed
+// print is passed to OnPrint as a 160 pixel wide grayscale image. Prints
+// without a bottom margin are still on the paper, later prints carry on
+// underneath them until one with a bottom margin finishes the sheet.
+// see https://gbdev.io/pandocs/Gameboy_Printer.html
+type Printer struct {
+	OnPrint func(print image.Image)
+
+	position   int    // where in a packet the next byte goes, see the packet constants
+	command    uint8  // command of the packet being received
+	compressed bool   // its data is run length encoded
+	length     int    // its data length
+	data       []byte // its data so far
+	sum        uint16 // the checksum as calculated so far
+	checksum   uint16 // the checksum as sent
+	status     uint8  // see the status bits
+	busy       int    // STATUS packets left before the print is done
+	buffer     []byte // tile data waiting to be printed
+	paper      []byte // shades printed on the current sheet, 160 per row
+}
+
+// Transfer receives a byte of a packet from the GameBoy, replying with 0x81
+// to say a printer is there and then with the status once the packet is in
+func (p *Printer) Transfer(out uint8) (in uint8) {
+	switch p.position {
+	case packetAck:
+		in = 0x81
+	case packetStatus:
+		in = p.status
+	}
+
+	p.receive(out)
+
+	return in
+}
+
+// Ready never completes, the printer has no clock of its own
+func (p *Printer) Ready(out uint8) (in uint8, ok bool) {
+	return 0, false
+}
+
+// receive moves through a packet a byte at a time:
+// 0x88 0x33, command, compression, length (little endian), data, checksum
+// (little endian) then 2 bytes for the replies
+func (p *Printer) receive(out uint8) {
+	if p.position >= packetCommand && p.position < packetChecksumLow {
+		p.sum += uint16(out)
+	}
+
+	switch p.position {
+	case packetMagic1:
+		if out != 0x88 {
+			return
+		}
+	case packetMagic2:
+		if out != 0x33 {
+			p.position = packetMagic1
+			return
+		}
+
+		p.sum = 0
+	case packetCommand:
+		p.command = out
+	case packetCompression:
+		p.compressed = out&0x01 != 0
+	case packetLengthLow:
+		p.length = int(out)
+	case packetLengthHigh:
+		p.length |= int(out) << 8
+		p.data = p.data[:0]
+
+		if p.length > 0 {
+			p.position = packetData
+			return
+		}
+
+		// nothing to receive
+		p.position = packetChecksumLow
+		return
+	case packetData:
+		p.data = append(p.data, out)
+
+		if len(p.data) < p.length {
+			return
+		}
+	case packetChecksumLow:
+		p.checksum = uint16(out)
+	case packetChecksumHigh:
+		p.checksum |= uint16(out) << 8
+		p.execute()
+	case packetStatus:
+		p.position = packetMagic1
+		return
+	}
+
+	p.position++
+}
+
+// execute runs a complete packet
+func (p *Printer) execute() {
+	if p.sum != p.checksum {
+		p.status |= printerChecksumError
+		return
+	}
+
+	p.status &^= printerChecksumError | printerPacketError
+
+	switch p.command {
+	case printerInit:
+		p.buffer = p.buffer[:0]
+		p.status, p.busy = 0, 0
+	case printerData:
+		if len(p.data) == 0 {
+			p.status |= printerFull
+			return
+		}
+
+		data := p.data
+		if p.compressed {
+			data = decompressRLE(data)
+		}
+
+		if len(p.buffer)+len(data) > printerBufferSize {
+			p.status |= printerPacketError
+			return
+		}
+
+		p.buffer = append(p.buffer, data...)
+		p.status |= printerUnprocessed
+	case printerPrint:
+		if len(p.data) != 4 {
+			p.status |= printerPacketError
+			return
+		}
+
+		p.print(p.data[0], p.data[1], p.data[2])
+		p.status = p.status&^(printerUnprocessed|printerFull) | printerBusy
+		p.busy = printerBusyPolls
+	case printerStatus:
+		if p.busy > 0 {
+			p.busy--
+
+			if p.busy == 0 {
+				p.status &^= printerBusy
+			}
+		}
+	default:
+		p.status |= printerPacketError
+	}
+}
+
+// decompressRLE expands a compressed DATA packet. A control byte with the top
+// bit set repeats the next byte (control & 0x7F) + 2 times, otherwise
+// control + 1 bytes follow as they are.
+func decompressRLE(data []byte) (out []byte) {
+	for i := 0; i < len(data); {
+		control := data[i]
+		i++
+
+		if control&0x80 != 0 {
+			if i >= len(data) {
+				break
+			}
+
+			for n := 0; n < int(control&0x7F)+2; n++ {
+				out = append(out, data[i])
+			}
+
+			i++
+			continue
+		}
+
+		n := min(int(control)+1, len(data)-i)
+		out = append(out, data[i:i+n]...)
+		i += n
+	}
+
+	return out
+}
+
+// print puts the buffer on the paper between its margins, the upper nibble
+// of margins is the line feeds before and the lower nibble the line feeds
+// after. Palette maps color indices to shades like BGP.
+func (p *Printer) print(sheets, margins, pal uint8) {
+	if pal == 0 {
+		// the printer treats 0 as the usual palette
+		pal = 0xE4
+	}
+
+	p.feed(margins >> 4)
+
+	rows := len(p.buffer) / printerTileRow
+	for sheet := 0; sheet < int(sheets); sheet++ {
+		for row := 0; row < rows; row++ {
+			for y := uint8(0); y < 8; y++ {
+				for x := 0; x < ScreenWidth; x++ {
+					tile := p.buffer[row*printerTileRow+x/8*16:]
+					index := tileColor(tile[y*2], tile[y*2+1], uint8(x%8))
+
+					p.paper = append(p.paper, palette(pal, index))
+				}
+			}
+		}
+	}
+
+	p.buffer = p.buffer[:0]
+
+	if margins&0x0F != 0 {
+		p.feed(margins & 0x0F)
+		p.Cut()
+	}
+}
+
+// feed advances the paper by some line feeds, leaving it blank
+func (p *Printer) feed(lines uint8) {
+	blank := make([]byte, int(lines)*printerFeedRows*ScreenWidth)
+	p.paper = append(p.paper, blank...)
+}
+
+// Cut tears off whatever has been printed, passing it to OnPrint even though
+// the GameBoy hasn't finished the sheet. It belongs to the GameBoy's
+// goroutine, like the rest of the printer.
+func (p *Printer) Cut() {
+	if len(p.paper) == 0 {
+		return
+	}
+
+	img := image.NewGray(image.Rect(0, 0, ScreenWidth, len(p.paper)/ScreenWidth))
+	for i, shade := range p.paper {
+		img.Pix[i] = shades[shade].R
+	}
+
+	p.paper = nil
+
+	if p.OnPrint != nil {
+		p.OnPrint(img)
+	}
+}
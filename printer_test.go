@@ -0,0 +1,162 @@
+package goboy
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sendPacket sends a packet to the printer, returning its replies to the 2
+// bytes after the checksum
+func sendPacket(t *testing.T, p *Printer, command uint8, compressed bool, data []byte) (ack, status uint8) {
+	packet := []byte{0x88, 0x33, command, 0x00, uint8(len(data)), uint8(len(data) >> 8)}
+	if compressed {
+		packet[3] = 0x01
+	}
+
+	packet = append(packet, data...)
+
+	var sum uint16
+	for _, b := range packet[2:] {
+		sum += uint16(b)
+	}
+
+	packet = append(packet, uint8(sum), uint8(sum>>8))
+
+	for _, b := range packet {
+		assert.Equal(t, uint8(0x00), p.Transfer(b))
+	}
+
+	return p.Transfer(0x00), p.Transfer(0x00)
+}
+
+// band is a DATA packet's worth of tiles, every pixel color index
+func band(index uint8) (data []byte) {
+	lo, hi := -(index & 1), -(index >> 1)
+
+	for i := 0; i < printerTileRow*2; i += 2 {
+		data = append(data, lo, hi)
+	}
+
+	return data
+}
+
+func TestPrinterPackets(t *testing.T) {
+	p := &Printer{}
+
+	ack, status := sendPacket(t, p, printerInit, false, nil)
+	assert.Equal(t, uint8(0x81), ack)
+	assert.Equal(t, uint8(0x00), status)
+
+	_, status = sendPacket(t, p, printerData, false, band(1))
+	assert.Equal(t, printerUnprocessed, status)
+
+	_, status = sendPacket(t, p, printerData, false, nil)
+	assert.Equal(t, printerUnprocessed|printerFull, status)
+
+	// a bad checksum is reported and the packet ignored
+	for _, b := range []byte{0x88, 0x33, printerInit, 0x00, 0x00, 0x00, 0x02, 0x00} {
+		p.Transfer(b)
+	}
+
+	p.Transfer(0x00)
+	assert.Equal(t, printerUnprocessed|printerFull|printerChecksumError, p.Transfer(0x00))
+	assert.Len(t, p.buffer, printerTileRow*2)
+
+	// noise between packets is skipped
+	p.Transfer(0x12)
+	p.Transfer(0x88)
+	p.Transfer(0x00)
+
+	_, status = sendPacket(t, p, printerStatus, false, nil)
+	assert.Equal(t, printerUnprocessed|printerFull, status)
+}
+
+func TestPrinterRLE(t *testing.T) {
+	data := []byte{
+		0x82, 0xAA, // 4 times 0xAA
+		0x02, 0x01, 0x02, 0x03, // 3 bytes as they are
+		0x80, 0xFF, // twice 0xFF
+	}
+
+	assert.Equal(t, []byte{0xAA, 0xAA, 0xAA, 0xAA, 0x01, 0x02, 0x03, 0xFF, 0xFF}, decompressRLE(data))
+
+	// a whole band of color 3 in 5 runs of 0xFF, 4 of 129 and 1 of 124
+	p := &Printer{}
+	compressed := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFA, 0xFF}
+
+	sendPacket(t, p, printerData, true, compressed)
+	assert.Equal(t, band(3), p.buffer)
+}
+
+func TestPrinterPrint(t *testing.T) {
+	var prints []image.Image
+
+	p := &Printer{
+		OnPrint: func(print image.Image) {
+			prints = append(prints, print)
+		},
+	}
+
+	sendPacket(t, p, printerInit, false, nil)
+	sendPacket(t, p, printerData, false, band(1))
+	sendPacket(t, p, printerData, false, band(3))
+	sendPacket(t, p, printerData, false, nil)
+
+	// no bottom margin, the paper stays in the printer
+	_, status := sendPacket(t, p, printerPrint, false, []byte{1, 0x10, 0xE4, 0x40})
+	assert.Equal(t, printerBusy, status)
+	assert.Empty(t, prints)
+
+	// busy for a while
+	for i := 1; i < printerBusyPolls; i++ {
+		_, status = sendPacket(t, p, printerStatus, false, nil)
+		assert.Equal(t, printerBusy, status)
+	}
+
+	_, status = sendPacket(t, p, printerStatus, false, nil)
+	assert.Equal(t, uint8(0x00), status)
+
+	// the next print carries on down the paper with an inverted palette, and
+	// the bottom margin finishes it
+	sendPacket(t, p, printerData, false, band(1))
+	sendPacket(t, p, printerPrint, false, []byte{1, 0x02, 0x1B, 0x40})
+
+	if !assert.Len(t, prints, 1) {
+		return
+	}
+
+	img := prints[0].(*image.Gray)
+	assert.Equal(t, image.Rect(0, 0, ScreenWidth, (1+2+1+2)*printerFeedRows), img.Bounds())
+
+	pixel := func(y int) uint8 {
+		return img.GrayAt(80, y).Y
+	}
+
+	assert.Equal(t, shades[0].R, pixel(0), "top margin")
+	assert.Equal(t, shades[1].R, pixel(printerFeedRows))
+	assert.Equal(t, shades[3].R, pixel(printerFeedRows*2))
+	assert.Equal(t, shades[2].R, pixel(printerFeedRows*3), "inverted palette")
+	assert.Equal(t, shades[0].R, pixel(printerFeedRows*4), "bottom margin")
+
+	// Cut tears off anything left over
+	sendPacket(t, p, printerData, false, band(3))
+	sendPacket(t, p, printerPrint, false, []byte{1, 0x00, 0xE4, 0x40})
+	p.Cut()
+	assert.Len(t, prints, 2)
+	assert.Equal(t, printerFeedRows, prints[1].Bounds().Dy())
+}
+
+func TestPrinterSerial(t *testing.T) {
+	gb := &GameBoy{Link: &Printer{}}
+
+	// the GameBoy drives the clock, the printer answers the byte after a packet
+	for _, b := range []byte{0x88, 0x33, printerStatus, 0x00, 0x00, 0x00, printerStatus, 0x00, 0x00} {
+		gb.WriteMemory(SB, b)
+		gb.WriteMemory(SC, 0x81)
+		ticks(gb, serialBitTicks*8)
+	}
+
+	assert.Equal(t, uint8(0x81), gb.ReadMemory(SB))
+}
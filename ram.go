@@ -3,30 +3,213 @@ package goboy
 // memory mapped addresses
 const (
 	JOYP = 0xFF00 // 65280
+	SB   = 0xFF01 // 65281
+	SC   = 0xFF02 // 65282
+	DIV  = 0xFF04 // 65284
+	TIMA = 0xFF05 // 65285
+	TMA  = 0xFF06 // 65286
+	TAC  = 0xFF07 // 65287
+	IF   = 0xFF0F // 65295
+	NR10 = 0xFF10 // 65296
+	NR11 = 0xFF11 // 65297
+	NR12 = 0xFF12 // 65298
+	NR13 = 0xFF13 // 65299
+	NR14 = 0xFF14 // 65300
+	NR21 = 0xFF16 // 65302
+	NR22 = 0xFF17 // 65303
+	NR23 = 0xFF18 // 65304
+	NR24 = 0xFF19 // 65305
+	NR30 = 0xFF1A // 65306
+	NR31 = 0xFF1B // 65307
+	NR32 = 0xFF1C // 65308
+	NR33 = 0xFF1D // 65309
+	NR34 = 0xFF1E // 65310
+	NR41 = 0xFF20 // 65312
+	NR42 = 0xFF21 // 65313
+	NR43 = 0xFF22 // 65314
+	NR44 = 0xFF23 // 65315
+	NR50 = 0xFF24 // 65316
+	NR51 = 0xFF25 // 65317
+	NR52 = 0xFF26 // 65318
+	LCDC = 0xFF40 // 65344
+	STAT = 0xFF41 // 65345
+	SCY  = 0xFF42 // 65346
+	SCX  = 0xFF43 // 65347
+	LY   = 0xFF44 // 65348
+	LYC  = 0xFF45 // 65349
+	DMA  = 0xFF46 // 65350
+	BGP  = 0xFF47 // 65351
+	OBP0 = 0xFF48 // 65352
+	OBP1 = 0xFF49 // 65353
+	WY   = 0xFF4A // 65354
+	WX   = 0xFF4B // 65355
+	BOOT = 0xFF50 // 65360
+	IE   = 0xFFFF // 65535
 )
 
 // ReadMemory reads a byte from memory at a given address, respecting memory mapping
 func (gb *GameBoy) ReadMemory(address uint16) (value byte) {
-	return gb.memory[address]
+	if gb.dmaConflict(address) {
+		if address >= 0xFE00 {
+			// OAM
+			return 0xFF
+		}
+
+		// the bus is busy with the byte DMA is copying
+		return gb.dma.value
+	}
+
+	switch {
+	case address < 0x0100 && gb.bootMapped: // boot ROM overlay
+		return gb.bootROM[address]
+	case address < 0x8000: // cartridge ROM
+		return gb.ReadRom8(address)
+	case address < 0xA000: // VRAM
+		if gb.vramBlocked() {
+			return 0xFF
+		}
+
+		return gb.vram[address-0x8000]
+	case address < 0xC000: // cartridge RAM
+		return gb.readCartRAM(address)
+	case address < 0xE000: // WRAM
+		return gb.wram[address-0xC000]
+	case address < 0xFE00: // echo of 0xC000-0xDDFF
+		return gb.wram[address-0xE000]
+	case address < 0xFEA0: // OAM
+		if gb.oamBlocked() {
+			return 0xFF
+		}
+
+		return gb.oam[address-0xFE00]
+	case address < 0xFF00: // unusable, reads back as 0 on the DMG
+		return 0x00
+	case address < 0xFF80: // I/O registers
+		return gb.readIO(address)
+	case address < 0xFFFF: // HRAM
+		return gb.hram[address-0xFF80]
+	default:
+		return gb.ie
+	}
 }
 
 // WriteMemory sets the value at a given address in memory, respecting memory mapping
 func (gb *GameBoy) WriteMemory(address uint16, value byte) {
-	gb.memory[address] = value
+	if gb.dmaConflict(address) {
+		// DMA has the bus
+		return
+	}
+
+	switch {
+	case address < 0x8000: // cartridge ROM
+		gb.WriteRom(address, value)
+	case address < 0xA000: // VRAM
+		if !gb.vramBlocked() {
+			gb.vram[address-0x8000] = value
+		}
+	case address < 0xC000: // cartridge RAM
+		gb.writeCartRAM(address, value)
+	case address < 0xE000: // WRAM
+		gb.wram[address-0xC000] = value
+	case address < 0xFE00: // echo of 0xC000-0xDDFF
+		gb.wram[address-0xE000] = value
+	case address < 0xFEA0: // OAM
+		if !gb.oamBlocked() {
+			gb.oam[address-0xFE00] = value
+		}
+	case address < 0xFF00: // unusable, writes are ignored
+	case address < 0xFF80: // I/O registers
+		gb.writeIO(address, value)
+	case address < 0xFFFF: // HRAM
+		gb.hram[address-0xFF80] = value
+	default:
+		gb.ie = value
+	}
+}
+
+// readMemory16 reads a little endian word from memory at a given address
+func (gb *GameBoy) readMemory16(address uint16) (value uint16) {
+	lsb := gb.ReadMemory(address)
+	msb := gb.ReadMemory(address + 1)
+	return mergeBytes(msb, lsb)
+}
+
+func (gb *GameBoy) readIO(address uint16) (value byte) {
+	if address >= NR10 && address < LCDC {
+		return gb.readAPU(address)
+	}
+
+	switch address {
+	case BOOT:
+		// write only
+		return 0xFF
+	case JOYP:
+		return gb.readJoypad()
+	case SB, SC:
+		return gb.readSerial(address)
+	case DIV, TIMA, TMA, TAC:
+		return gb.readTimer(address)
+	case LCDC, STAT, SCY, SCX, LY, LYC, BGP, OBP0, OBP1, WY, WX:
+		return gb.readPPU(address)
+	case DMA:
+		return gb.dma.register
+	case IF:
+		// unused bits read as 1
+		return gb.io[IF-0xFF00] | ^interruptMask
+	}
+
+	return gb.io[address-0xFF00]
+}
+
+func (gb *GameBoy) writeIO(address uint16, value byte) {
+	if address >= NR10 && address < LCDC {
+		gb.writeAPU(address, value)
+		return
+	}
+
+	switch address {
+	case BOOT:
+		// any non-zero write unmaps the boot ROM until the next reset
+		if value != 0 {
+			gb.bootMapped = false
+		}
+		return
+	case JOYP:
+		gb.writeJoypad(value)
+		return
+	case SB, SC:
+		gb.writeSerial(address, value)
+		return
+	case DIV, TIMA, TMA, TAC:
+		gb.writeTimer(address, value)
+		return
+	case LCDC, STAT, SCY, SCX, LY, LYC, BGP, OBP0, OBP1, WY, WX:
+		gb.writePPU(address, value)
+		return
+	case DMA:
+		gb.startDMA(value)
+		return
+	case IF:
+		value &= interruptMask
+	}
+
+	gb.io[address-0xFF00] = value
 }
 
+// PushStack pushes a word onto the stack like the CPU does, taking 2 M-cycles
 func (gb *GameBoy) PushStack(value uint16) {
 	msb, lsb := splitBytes(value)
 	gb.sp--
-	gb.WriteMemory(gb.sp, msb)
+	gb.cpuWrite(gb.sp, msb)
 	gb.sp--
-	gb.WriteMemory(gb.sp, lsb)
+	gb.cpuWrite(gb.sp, lsb)
 }
 
+// PopStack pops a word off the stack like the CPU does, taking 2 M-cycles
 func (gb *GameBoy) PopStack() (value uint16) {
-	lsb := gb.ReadMemory(gb.sp)
+	lsb := gb.cpuRead(gb.sp)
 	gb.sp++
-	msb := gb.ReadMemory(gb.sp)
+	msb := gb.cpuRead(gb.sp)
 	gb.sp++
 
 	return mergeBytes(msb, lsb)
@@ -0,0 +1,48 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryMap(t *testing.T) {
+	rom := newTestROM(0x00, 0x00, 0x00)
+	rom[0x0150] = 0x42
+
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(rom))
+
+	// cartridge ROM is visible through the bus
+	assert.Equal(t, uint8(0x42), gb.ReadMemory(0x0150))
+
+	// WRAM is echoed from 0xE000
+	gb.WriteMemory(0xC123, 0x12)
+	assert.Equal(t, uint8(0x12), gb.ReadMemory(0xE123))
+	gb.WriteMemory(0xFDFF, 0x34)
+	assert.Equal(t, uint8(0x34), gb.ReadMemory(0xDDFF))
+
+	// the unusable region ignores writes
+	gb.WriteMemory(0xFEA0, 0x56)
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(0xFEA0))
+
+	// HRAM and IE are distinct
+	gb.WriteMemory(0xFFFE, 0x78)
+	gb.WriteMemory(IE, 0x1F)
+	assert.Equal(t, uint8(0x78), gb.ReadMemory(0xFFFE))
+	assert.Equal(t, uint8(0x1F), gb.ReadMemory(IE))
+}
+
+func TestExecuteFromWRAM(t *testing.T) {
+	gb := &GameBoy{}
+
+	// LD A, 0x99
+	gb.WriteMemory(0xC000, 0x3E)
+	gb.WriteMemory(0xC001, 0x99)
+	gb.pc = 0xC000
+
+	gb.RunInstruction()
+
+	assert.Equal(t, uint8(0x99), gb.a)
+	assert.Equal(t, uint16(0xC002), gb.pc)
+}
@@ -0,0 +1,281 @@
+package goboy
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+const (
+	maxSpritesPerLine = 10
+	oamEntries        = 40
+)
+
+// sprite attribute bits, the 4th byte of an OAM entry
+const (
+	attrPalette  uint8 = 1 << 4 // OBP1 instead of OBP0
+	attrFlipX    uint8 = 1 << 5
+	attrFlipY    uint8 = 1 << 6
+	attrPriority uint8 = 1 << 7 // background colors 1-3 are drawn over the sprite
+)
+
+// shades are the colors of the 4 shades a palette maps color indices to
+var shades = [4]color.RGBA{
+	argb(White),
+	argb(LightGray),
+	argb(DarkGray),
+	argb(Black),
+}
+
+func argb(c uint32) (rgba color.RGBA) {
+	return color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: uint8(c >> 24)}
+}
+
+// sprite is an OAM entry selected for the current line
+type sprite struct {
+	y, x  uint8
+	tile  uint8
+	attrs uint8
+}
+
+// Frame returns the last complete frame. The image belongs to the GameBoy and
+// is redrawn by every call, copy it to keep it.
+func (gb *GameBoy) Frame() (frame *image.RGBA) {
+	p := &gb.ppu
+
+	if p.image == nil {
+		p.image = image.NewRGBA(image.Rect(0, 0, ScreenWidth, ScreenHeight))
+	}
+
+	for i, shade := range p.front {
+		c := shades[shade]
+		pix := p.image.Pix[i*4 : i*4+4]
+		pix[0], pix[1], pix[2], pix[3] = c.R, c.G, c.B, c.A
+	}
+
+	return p.image
+}
+
+// presentFrame makes the frame drawn so far the one Frame returns, apart from
+// the first frame after the LCD is turned on which is never shown
+func (gb *GameBoy) presentFrame() {
+	p := &gb.ppu
+
+	if p.hidden {
+		p.hidden = false
+		return
+	}
+
+	p.front = p.back
+}
+
+// tileRow returns the 2 bytes for row y of a tile, in either addressing mode.
+// The 0x8000 mode has indices 0-255 from 0x8000, the other has -128-127
+// relative to 0x9000. Sprites always use the first.
+func (gb *GameBoy) tileRow(index uint8, y uint8, unsigned bool) (lo, hi uint8) {
+	var address int
+	if unsigned {
+		address = int(index) * 16
+	} else {
+		address = 0x1000 + int(int8(index))*16
+	}
+
+	address += int(y) * 2
+
+	return gb.vram[address], gb.vram[address+1]
+}
+
+// tileColor is the 2 bit color index of pixel x (0 is the leftmost) in a tile row
+func tileColor(lo, hi uint8, x uint8) (index uint8) {
+	bit := 7 - x
+
+	return (lo>>bit)&1 | ((hi>>bit)&1)<<1
+}
+
+// mapTile is the tile index at (x, y), in tiles, from the selected tile map
+func (gb *GameBoy) mapTile(highMap bool, x, y uint8) (index uint8) {
+	address := 0x1800 + int(y)*32 + int(x)
+	if highMap {
+		address += 0x400
+	}
+
+	return gb.vram[address]
+}
+
+// palette maps a color index to a shade
+func palette(palette uint8, index uint8) (shade uint8) {
+	return (palette >> (index * 2)) & 0x03
+}
+
+// renderLine draws the current line into the back buffer, with the registers
+// as they are at the start of drawing
+func (gb *GameBoy) renderLine() {
+	p := &gb.ppu
+	ly := p.line
+
+	// background color indices, sprites need them for priority
+	var bg [ScreenWidth]uint8
+
+	// once LY matches WY the window is drawn for the rest of the frame
+	if ly == p.wy {
+		p.windowTriggered = true
+	}
+
+	if p.lcdc&lcdcBGEnable != 0 {
+		gb.renderBackground(&bg)
+		gb.renderWindow(&bg)
+	}
+
+	line := p.back[int(ly)*ScreenWidth : int(ly+1)*ScreenWidth]
+	for x, index := range bg {
+		if p.lcdc&lcdcBGEnable == 0 {
+			// blank, whatever the palette says
+			line[x] = 0
+			continue
+		}
+
+		line[x] = palette(p.bgp, index)
+	}
+
+	if p.lcdc&lcdcOBJEnable != 0 {
+		gb.renderSprites(&bg, line)
+	}
+}
+
+func (gb *GameBoy) renderBackground(bg *[ScreenWidth]uint8) {
+	p := &gb.ppu
+
+	y := p.line + p.scy
+	unsigned := p.lcdc&lcdcTileData != 0
+	highMap := p.lcdc&lcdcBGTileMap != 0
+
+	for x := range bg {
+		bx := uint8(x) + p.scx
+
+		lo, hi := gb.tileRow(gb.mapTile(highMap, bx/8, y/8), y%8, unsigned)
+		bg[x] = tileColor(lo, hi, bx%8)
+	}
+}
+
+// renderWindow draws the window over the background. It only starts once LY
+// has matched WY during the frame, and has its own line counter that only
+// advances on lines it was drawn on.
+func (gb *GameBoy) renderWindow(bg *[ScreenWidth]uint8) {
+	p := &gb.ppu
+
+	if p.lcdc&lcdcWindowEnable == 0 || !p.windowTriggered || p.wx > 166 {
+		return
+	}
+
+	y := p.windowLine
+	p.windowLine++
+
+	unsigned := p.lcdc&lcdcTileData != 0
+	highMap := p.lcdc&lcdcWindowTileMap != 0
+
+	for x := int(p.wx) - 7; x < ScreenWidth; x++ {
+		if x < 0 {
+			continue
+		}
+
+		wx := uint8(x + 7 - int(p.wx))
+
+		lo, hi := gb.tileRow(gb.mapTile(highMap, wx/8, y/8), y%8, unsigned)
+		bg[x] = tileColor(lo, hi, wx%8)
+	}
+}
+
+// scanOAM picks the sprites on the current line, the first 10 in OAM order.
+// They are returned in drawing priority, lowest X first and OAM order on ties.
+func (gb *GameBoy) scanOAM() (sprites []sprite) {
+	p := &gb.ppu
+
+	height := uint8(8)
+	if p.lcdc&lcdcOBJSize != 0 {
+		height = 16
+	}
+
+	for i := 0; i < oamEntries && len(sprites) < maxSpritesPerLine; i++ {
+		entry := gb.oam[i*4 : i*4+4]
+
+		// Y is offset by 16 so sprites can be partially above the screen
+		top := int(entry[0]) - 16
+		if int(p.line) < top || int(p.line) >= top+int(height) {
+			continue
+		}
+
+		sprites = append(sprites, sprite{entry[0], entry[1], entry[2], entry[3]})
+	}
+
+	// stable, so OAM order breaks ties
+	sort.SliceStable(sprites, func(i, j int) bool {
+		return sprites[i].x < sprites[j].x
+	})
+
+	return sprites
+}
+
+// spriteRow returns the row of the sprite's tile on the current line
+func (gb *GameBoy) spriteRow(s sprite) (lo, hi uint8) {
+	p := &gb.ppu
+
+	row := p.line + 16 - s.y
+	tile := s.tile
+
+	if p.lcdc&lcdcOBJSize != 0 {
+		if s.attrs&attrFlipY != 0 {
+			row = 15 - row
+		}
+
+		// the bottom half is always the odd tile
+		tile = tile&0xFE | row/8
+		row %= 8
+	} else if s.attrs&attrFlipY != 0 {
+		row = 7 - row
+	}
+
+	return gb.tileRow(tile, row, true)
+}
+
+func (gb *GameBoy) renderSprites(bg *[ScreenWidth]uint8, line []uint8) {
+	p := &gb.ppu
+
+	// whether a sprite pixel was drawn, higher priority sprites come first
+	var drawn [ScreenWidth]bool
+
+	for _, s := range gb.scanOAM() {
+		lo, hi := gb.spriteRow(s)
+
+		pal := p.obp0
+		if s.attrs&attrPalette != 0 {
+			pal = p.obp1
+		}
+
+		// X is offset by 8 so sprites can be partially left of the screen
+		for px := uint8(0); px < 8; px++ {
+			x := int(s.x) - 8 + int(px)
+			if x < 0 || x >= ScreenWidth || drawn[x] {
+				continue
+			}
+
+			tx := px
+			if s.attrs&attrFlipX != 0 {
+				tx = 7 - px
+			}
+
+			index := tileColor(lo, hi, tx)
+			if index == 0 {
+				// transparent, a lower priority sprite can still show through
+				continue
+			}
+
+			drawn[x] = true
+
+			if s.attrs&attrPriority != 0 && bg[x] != 0 {
+				continue
+			}
+
+			line[x] = palette(pal, index)
+		}
+	}
+}
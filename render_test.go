@@ -0,0 +1,251 @@
+package goboy
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// solidTile fills the tile at address with a single color index
+func solidTile(gb *GameBoy, address uint16, index uint8) {
+	for i := uint16(0); i < 16; i += 2 {
+		gb.vram[address-0x8000+i] = 0xFF * (index & 1)
+		gb.vram[address-0x8000+i+1] = 0xFF * (index >> 1)
+	}
+}
+
+// renderTestLine draws line ly and returns its shades
+func renderTestLine(gb *GameBoy, ly uint8) (line []uint8) {
+	gb.ppu.line = ly
+	gb.renderLine()
+
+	return gb.ppu.back[int(ly)*ScreenWidth : int(ly+1)*ScreenWidth]
+}
+
+// newTestRenderer returns a GameBoy with identity palettes, so shades match
+// color indices, and the given LCDC bits along with the LCD and BG
+func newTestRenderer(lcdc uint8) (gb *GameBoy) {
+	gb = &GameBoy{}
+	gb.ppu.lcdc = lcdcEnable | lcdcBGEnable | lcdc
+	gb.ppu.bgp, gb.ppu.obp0, gb.ppu.obp1 = 0xE4, 0xE4, 0xE4
+
+	return gb
+}
+
+func TestBackgroundTileData(t *testing.T) {
+	gb := newTestRenderer(lcdcTileData)
+	solidTile(gb, 0x8010, 3)
+	gb.vram[0x1800] = 1
+
+	line := renderTestLine(gb, 0)
+	assert.Equal(t, []uint8{3, 3, 3, 3, 3, 3, 3, 3, 0}, line[:9])
+
+	// signed addressing, tile 0xFF is the one right before 0x9000
+	gb = newTestRenderer(0)
+	solidTile(gb, 0x8FF0, 2)
+	solidTile(gb, 0x9000, 1)
+	gb.vram[0x1800] = 0xFF
+
+	line = renderTestLine(gb, 0)
+	assert.Equal(t, []uint8{2, 2, 2, 2, 2, 2, 2, 2, 1}, line[:9])
+
+	// the palette maps color indices to shades
+	gb.ppu.bgp = 0b00_01_11_10
+	line = renderTestLine(gb, 0)
+	assert.Equal(t, uint8(1), line[0])
+	assert.Equal(t, uint8(3), line[8])
+
+	// disabled background is blank
+	gb.ppu.lcdc &^= lcdcBGEnable
+	line = renderTestLine(gb, 0)
+	assert.Equal(t, make([]uint8, ScreenWidth), line)
+}
+
+func TestBackgroundScroll(t *testing.T) {
+	gb := newTestRenderer(lcdcTileData | lcdcBGTileMap)
+
+	// tile 1 only has its leftmost column set
+	for i := 0; i < 16; i += 2 {
+		gb.vram[0x10+i] = 0x80
+	}
+
+	gb.vram[0x1C01] = 1
+	gb.ppu.scx = 4
+
+	line := renderTestLine(gb, 0)
+	assert.Equal(t, uint8(1), line[4])
+	assert.Equal(t, uint8(0), line[3])
+	assert.Equal(t, uint8(0), line[5])
+
+	// wraps around to the other edge of the map
+	gb.ppu.scx = 250
+	line = renderTestLine(gb, 0)
+	assert.Equal(t, uint8(1), line[14])
+
+	gb.ppu.scx = 0
+	gb.ppu.scy = 0xF8
+	line = renderTestLine(gb, 8)
+	assert.Equal(t, uint8(1), line[8])
+	line = renderTestLine(gb, 7)
+	assert.Equal(t, uint8(0), line[8])
+}
+
+func TestWindow(t *testing.T) {
+	gb := newTestRenderer(lcdcTileData | lcdcWindowEnable | lcdcWindowTileMap)
+
+	// tile 2 is color 3 on its first row and color 1 on the rest
+	solidTile(gb, 0x8020, 1)
+	gb.vram[0x21] = 0xFF
+	gb.vram[0x1C00] = 2
+	gb.ppu.wy = 2
+	gb.ppu.wx = 7 + 80
+
+	assert.Equal(t, uint8(0), renderTestLine(gb, 1)[80], "above WY")
+
+	line := renderTestLine(gb, 2)
+	assert.Equal(t, uint8(0), line[79])
+	assert.Equal(t, uint8(3), line[80])
+
+	// the window's line counter doesn't advance while it is hidden
+	gb.ppu.lcdc &^= lcdcWindowEnable
+	assert.Equal(t, uint8(0), renderTestLine(gb, 3)[80])
+
+	gb.ppu.lcdc |= lcdcWindowEnable
+	assert.Equal(t, uint8(1), renderTestLine(gb, 4)[80])
+	assert.Equal(t, uint8(2), gb.ppu.windowLine)
+
+	// moving WY below LY doesn't hide it again
+	gb.ppu.wy = 100
+	assert.Equal(t, uint8(1), renderTestLine(gb, 5)[80])
+}
+
+func TestSprites(t *testing.T) {
+	gb := newTestRenderer(lcdcOBJEnable)
+	solidTile(gb, 0x8010, 3)
+	solidTile(gb, 0x8020, 1)
+	solidTile(gb, 0x9000, 0)
+
+	// y, x, tile, attributes
+	copy(gb.oam[:], []uint8{
+		16, 8, 1, 0,
+		16, 20, 1, 0,
+		16, 14, 2, 0,
+		16, 30, 1, attrPalette,
+	})
+	gb.ppu.obp1 = 0b01_00_00_00
+
+	line := renderTestLine(gb, 0)
+	assert.Equal(t, []uint8{3, 3, 3, 3, 3, 3, 3, 3}, line[:8])
+
+	// the lower X wins where sprites overlap
+	assert.Equal(t, uint8(1), line[6+5])
+	assert.Equal(t, uint8(1), line[13])
+	assert.Equal(t, uint8(3), line[14])
+
+	assert.Equal(t, uint8(1), line[22], "OBP1")
+
+	// below the sprites
+	assert.Equal(t, make([]uint8, ScreenWidth), renderTestLine(gb, 8))
+
+	// at the same X, OAM order wins
+	copy(gb.oam[:], []uint8{
+		16, 8, 2, 0,
+		16, 8, 1, 0,
+	})
+	assert.Equal(t, uint8(1), renderTestLine(gb, 0)[0])
+
+	// disabled sprites
+	gb.ppu.lcdc &^= lcdcOBJEnable
+	assert.Equal(t, uint8(0), renderTestLine(gb, 0)[0])
+}
+
+func TestSpriteLimit(t *testing.T) {
+	gb := newTestRenderer(lcdcOBJEnable)
+	solidTile(gb, 0x8010, 3)
+
+	for i := 0; i < 11; i++ {
+		copy(gb.oam[i*4:], []uint8{16, uint8(8 + i*10), 1, 0})
+	}
+
+	line := renderTestLine(gb, 0)
+	assert.Equal(t, uint8(3), line[90])
+	assert.Equal(t, uint8(0), line[100], "only the first 10 sprites on a line are drawn")
+
+	// off screen sprites still count
+	gb.oam[1] = 0
+	line = renderTestLine(gb, 0)
+	assert.Equal(t, uint8(0), line[0])
+	assert.Equal(t, uint8(0), line[100])
+}
+
+func TestSpriteAttributes(t *testing.T) {
+	gb := newTestRenderer(lcdcOBJEnable | lcdcTileData)
+
+	// tile 1 only has its top left pixel set
+	gb.vram[0x10] = 0x80
+	gb.vram[0x11] = 0x80
+
+	copy(gb.oam[:], []uint8{16, 8, 1, attrFlipX | attrFlipY})
+	assert.Equal(t, uint8(3), renderTestLine(gb, 7)[7])
+	assert.Equal(t, uint8(0), renderTestLine(gb, 0)[0])
+
+	// background colors 1-3 cover sprites with the priority attribute
+	solidTile(gb, 0x8020, 3)
+	copy(gb.oam[:], []uint8{16, 8, 2, attrPriority})
+	gb.vram[0x1800] = 1
+	solidTile(gb, 0x8010, 1)
+	solidTile(gb, 0x8000, 0)
+
+	line := renderTestLine(gb, 0)
+	assert.Equal(t, []uint8{1, 1, 1, 1, 1, 1, 1, 1, 0}, line[:9])
+
+	gb.vram[0x1800] = 0
+	line = renderTestLine(gb, 0)
+	assert.Equal(t, []uint8{3, 3, 3, 3, 3, 3, 3, 3, 0}, line[:9])
+}
+
+func TestTallSprites(t *testing.T) {
+	gb := newTestRenderer(lcdcOBJEnable | lcdcOBJSize)
+	solidTile(gb, 0x8020, 1)
+	solidTile(gb, 0x8030, 2)
+	solidTile(gb, 0x9000, 0)
+
+	// the lowest bit of the tile index is ignored
+	copy(gb.oam[:], []uint8{16, 8, 3, 0})
+	assert.Equal(t, uint8(1), renderTestLine(gb, 0)[0])
+	assert.Equal(t, uint8(2), renderTestLine(gb, 8)[0])
+	assert.Equal(t, uint8(2), renderTestLine(gb, 15)[0])
+	assert.Equal(t, uint8(0), renderTestLine(gb, 16)[0])
+
+	// flipping swaps the tiles
+	gb.oam[3] = attrFlipY
+	assert.Equal(t, uint8(2), renderTestLine(gb, 0)[0])
+	assert.Equal(t, uint8(1), renderTestLine(gb, 15)[0])
+}
+
+func TestFrame(t *testing.T) {
+	gb := newTestCPU(0x18, 0xFE) // JR -2
+	solidTile(gb, 0x9000, 3)
+	gb.WriteMemory(BGP, 0xE4)
+
+	assert.Equal(t, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}, gb.Frame().At(0, 0))
+
+	// the first frame after turning the LCD on isn't shown
+	gb.WriteMemory(LCDC, lcdcEnable|lcdcBGEnable)
+	gb.RunFrame()
+	assert.Equal(t, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}, gb.Frame().At(0, 0))
+
+	gb.RunFrame()
+	frame := gb.Frame()
+	assert.Equal(t, color.RGBA{0x00, 0x00, 0x00, 0xFF}, frame.At(0, 0))
+	assert.Equal(t, color.RGBA{0x00, 0x00, 0x00, 0xFF}, frame.At(ScreenWidth-1, ScreenHeight-1))
+
+	gb.WriteMemory(BGP, 0x40)
+	gb.RunFrame()
+	assert.Equal(t, color.RGBA{0xAA, 0xAA, 0xAA, 0xFF}, gb.Frame().At(80, 72))
+
+	// turning the LCD off blanks the screen
+	gb.WriteMemory(LCDC, 0)
+	assert.Equal(t, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}, gb.Frame().At(80, 72))
+}
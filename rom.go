@@ -2,38 +2,292 @@ package goboy
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 )
 
-func (gb *GameBoy) LoadROM(d []byte) {
+// cartridge header errors
+var (
+	ErrTruncatedROM   = errors.New("ROM is truncated")
+	ErrROMSize        = errors.New("unknown ROM size code")
+	ErrRAMSize        = errors.New("unknown RAM size code")
+	ErrLogo           = errors.New("Nintendo logo mismatch")
+	ErrHeaderChecksum = errors.New("header checksum mismatch")
+	ErrGlobalChecksum = errors.New("global checksum mismatch")
+)
+
+// headerEnd is the first address after the cartridge header
+const headerEnd = 0x0150
+
+// nintendoLogo is checked by the boot ROM against 0x0104-0x0133 of the cartridge
+var nintendoLogo = [48]uint8{
+	0xCE, 0xED, 0x66, 0x66, 0xCC, 0x0D, 0x00, 0x0B, 0x03, 0x73, 0x00, 0x83, 0x00, 0x0C, 0x00, 0x0D,
+	0x00, 0x08, 0x11, 0x1F, 0x88, 0x89, 0x00, 0x0E, 0xDC, 0xCC, 0x6E, 0xE6, 0xDD, 0xDD, 0xD9, 0x99,
+	0xBB, 0xBB, 0x67, 0x63, 0x6E, 0x0E, 0xEC, 0xCC, 0xDD, 0xDC, 0x99, 0x9F, 0xBB, 0xB9, 0x33, 0x3E,
+}
+
+// CartridgeHeader is the metadata stored at 0x0100-0x014F of every cartridge
+// https://gbdev.io/pandocs/The_Cartridge_Header.html
+type CartridgeHeader struct {
+	Title            string // upper case ASCII, up to 16 characters on older cartridges
+	ManufacturerCode string // 4 characters, only on newer cartridges
+	CGBFlag          uint8  // 0x80 supports CGB functions, 0xC0 requires a CGB
+	SGBFlag          uint8  // 0x03 supports SGB functions
+	NewLicenseeCode  string // 2 characters, only used when OldLicenseeCode is 0x33
+	OldLicenseeCode  uint8
+	CartridgeType    uint8 // mapper and extra hardware, see https://gbdev.io/pandocs/The_Cartridge_Header.html#0147--cartridge-type
+	ROMSizeCode      uint8 // see ROMSize
+	RAMSizeCode      uint8 // see RAMSize
+	DestinationCode  uint8 // 0x00 Japan, 0x01 overseas
+	Version          uint8
+	HeaderChecksum   uint8
+	GlobalChecksum   uint16
+
+	logo          [48]uint8
+	headerSum     uint8  // computed over 0x0134-0x014C
+	globalSum     uint16 // computed over the whole ROM, minus the global checksum itself
+	romDataLength int
+}
+
+// ParseCartridgeHeader reads the cartridge header out of a ROM image
+func ParseCartridgeHeader(d []byte) (header *CartridgeHeader, err error) {
+	if len(d) < headerEnd {
+		return nil, errors.Wrapf(ErrTruncatedROM, "%d bytes is too short for a cartridge header", len(d))
+	}
+
+	header = &CartridgeHeader{
+		CGBFlag:         d[0x0143],
+		SGBFlag:         d[0x0146],
+		OldLicenseeCode: d[0x014B],
+		CartridgeType:   d[0x0147],
+		ROMSizeCode:     d[0x0148],
+		RAMSizeCode:     d[0x0149],
+		DestinationCode: d[0x014A],
+		Version:         d[0x014C],
+		HeaderChecksum:  d[0x014D],
+		GlobalChecksum:  mergeBytes(d[0x014E], d[0x014F]), // big endian, unlike everything else
+	}
+
+	copy(header.logo[:], d[0x0104:0x0134])
+
+	if header.CGBFlag&0x80 != 0 {
+		// 0x0143 is the CGB flag instead of the last character of the title,
+		// newer cartridges also shortened the title to fit a manufacturer code
+		header.Title = headerString(d[0x0134:0x0143])
+
+		manufacturer := headerString(d[0x013F:0x0143])
+		if len(manufacturer) == 4 {
+			header.ManufacturerCode = manufacturer
+			header.Title = headerString(d[0x0134:0x013F])
+		}
+	} else {
+		header.Title = headerString(d[0x0134:0x0144])
+	}
+
+	if header.OldLicenseeCode == 0x33 {
+		header.NewLicenseeCode = headerString(d[0x0144:0x0146])
+	}
+
+	for i := 0x0134; i <= 0x014C; i++ {
+		header.headerSum = header.headerSum - d[i] - 1
+	}
+
+	for i, b := range d {
+		if i != 0x014E && i != 0x014F {
+			header.globalSum += uint16(b)
+		}
+	}
+
+	return header, nil
+}
+
+// headerString trims a NUL padded header field down to its printable characters
+func headerString(d []byte) (s string) {
+	var sb strings.Builder
+
+	for _, c := range d {
+		if c == 0 {
+			break
+		}
+
+		if c < 0x20 || c > 0x7E {
+			// not text, likely part of a different field
+			return sb.String()
+		}
+
+		sb.WriteByte(c)
+	}
+
+	return sb.String()
+}
+
+// ROMSize is the size of the ROM in bytes, as declared by the header
+func (h *CartridgeHeader) ROMSize() (size int, err error) {
+	switch {
+	case h.ROMSizeCode <= 0x08:
+		return 0x8000 << h.ROMSizeCode, nil
+	case h.ROMSizeCode == 0x52: // 72 banks
+		return 72 * 0x4000, nil
+	case h.ROMSizeCode == 0x53: // 80 banks
+		return 80 * 0x4000, nil
+	case h.ROMSizeCode == 0x54: // 96 banks
+		return 96 * 0x4000, nil
+	}
+
+	return 0, errors.Wrapf(ErrROMSize, "0x%.2X", h.ROMSizeCode)
+}
+
+// RAMSize is the size of the external RAM in bytes, as declared by the header
+func (h *CartridgeHeader) RAMSize() (size int, err error) {
+	switch h.RAMSizeCode {
+	case 0x00:
+		return 0, nil
+	case 0x01: // unofficial, listed by some homebrew
+		return 0x0800, nil
+	case 0x02:
+		return 0x2000, nil
+	case 0x03:
+		return 0x8000, nil
+	case 0x04:
+		return 0x20000, nil
+	case 0x05:
+		return 0x10000, nil
+	}
+
+	return 0, errors.Wrapf(ErrRAMSize, "0x%.2X", h.RAMSizeCode)
+}
+
+// Validate checks the header's Nintendo logo and both checksums, returning the
+// first mismatch. Only the logo and the header checksum are checked by the boot
+// ROM, plenty of working cartridges have a wrong global checksum.
+func (h *CartridgeHeader) Validate() (err error) {
+	if h.logo != nintendoLogo {
+		return ErrLogo
+	}
+
+	err = h.checkHeaderSum()
+	if err != nil {
+		return err
+	}
+
+	if h.globalSum != h.GlobalChecksum {
+		return errors.Wrapf(ErrGlobalChecksum, "expected 0x%.4X, got 0x%.4X", h.GlobalChecksum, h.globalSum)
+	}
+
+	return nil
+}
+
+func (h *CartridgeHeader) checkHeaderSum() (err error) {
+	if h.headerSum != h.HeaderChecksum {
+		return errors.Wrapf(ErrHeaderChecksum, "expected 0x%.2X, got 0x%.2X", h.HeaderChecksum, h.headerSum)
+	}
+
+	return nil
+}
+
+// LoadROM inserts a cartridge and resets the GameBoy. The image is rejected if
+// its header is damaged or it's shorter than the header claims, the logo and
+// global checksum aren't enforced, see CartridgeHeader.Validate.
+func (gb *GameBoy) LoadROM(d []byte) (err error) {
+	header, err := ParseCartridgeHeader(d)
+	if err != nil {
+		return err
+	}
+
+	err = header.checkHeaderSum()
+	if err != nil {
+		return err
+	}
+
+	romSize, err := header.ROMSize()
+	if err != nil {
+		return err
+	}
+
+	if len(d) < romSize {
+		return errors.Wrapf(ErrTruncatedROM, "header declares %d bytes, got %d", romSize, len(d))
+	}
+
+	_, err = header.RAMSize()
+	if err != nil {
+		return err
+	}
+
+	m, err := newMBC(header, d, gb.now, gb.rumble)
+	if err != nil {
+		return err
+	}
+
 	gb.romData = d
-	gb.pc = 0
+	gb.header = header
+	gb.mbc = m
+	gb.ramDirty = false
+	gb.Reset()
+
+	return nil
+}
+
+// Header is the parsed header of the loaded cartridge, nil if there isn't one
+func (gb *GameBoy) Header() (header *CartridgeHeader) {
+	return gb.header
 }
 
 // ReadRom8 reads a byte from Rom at a given address, respecting Rom mapping
 func (gb *GameBoy) ReadRom8(address uint16) (value byte) {
-	return gb.romData[address]
+	if gb.mbc == nil {
+		// no cartridge, nothing drives the bus so it floats high
+		return 0xFF
+	}
+
+	return gb.mbc.readROM(address)
 }
 
 func (gb *GameBoy) ReadRom16(address uint16) (value uint16) {
-	lsb := gb.romData[address] // little endian
-	msb := gb.romData[address+1]
+	lsb := gb.ReadRom8(address) // little endian
+	msb := gb.ReadRom8(address + 1)
 	return mergeBytes(msb, lsb)
 }
 
-// WriteRom sets the value at a given address in Rom, respecting Rom mapping
+// WriteRom sends a write to the Rom area, the cartridge's memory bank
+// controller treats it as a register write. Rom itself never changes.
 func (gb *GameBoy) WriteRom(address uint16, value byte) {
-	gb.romData[address] = value
+	if gb.mbc == nil {
+		return
+	}
+
+	gb.mbc.writeROM(address, value)
 }
 
+// readCartRAM reads a byte from the cartridge's external RAM, 0xA000-0xBFFF
+func (gb *GameBoy) readCartRAM(address uint16) (value byte) {
+	if gb.mbc == nil {
+		return 0xFF
+	}
+
+	return gb.mbc.readRAM(address)
+}
+
+// writeCartRAM writes a byte to the cartridge's external RAM, 0xA000-0xBFFF
+func (gb *GameBoy) writeCartRAM(address uint16, value byte) {
+	if gb.mbc == nil {
+		return
+	}
+
+	if gb.mbc.writeRAM(address, value) && gb.header.HasBattery() {
+		gb.ramDirty = true
+	}
+}
+
+// RunFrame runs instructions until the start of the next VBlank. With the LCD
+// off there isn't one, so it runs a frame's worth of cycles instead.
 // inspired by https://docs.libretro.com/development/cores/developing-cores/#retro_run
 func (gb *GameBoy) RunFrame() {
-	// this would probably continuously
-	// run instructions until a VBlank interrupt
+	gb.frameDone = false
+	end := gb.tickCount + CyclesPerFrame
 
-	for i := 0; i <= 24603; i++ {
+	for i := 0; !gb.frameDone && gb.tickCount < end; i++ {
 		gb.debugLnF("instruction #%d", i)
 
 		if gb.pc == 0x00A0 {
@@ -50,12 +304,38 @@ func (gb *GameBoy) RunInstruction() {
 		gb.debugLnF("instruction ET: %s\n", time.Since(start))
 	}()
 
+	if gb.handleInterrupts() {
+		return
+	}
+
+	if gb.halted || gb.stopped {
+		// suspended, time passes but nothing is fetched
+		gb.tick()
+		return
+	}
+
+	// every access below ticks, whatever the operation doesn't account for
+	// itself is spent in internal delays at the end
+	firstTick := gb.tickCount
+
 	// first byte of instruction might be a prefix
 	gb.debugLnF("PC: %.4X", gb.pc)
 
-	prefix := gb.ReadRom8(gb.pc)
+	// EI takes effect after the instruction following it
+	if gb.eiPending {
+		gb.eiPending = false
+		gb.ime = true
+	}
+
+	prefix := gb.cpuRead(gb.pc)
 	offset := uint16(1)
 
+	if gb.haltBug {
+		// PC fails to increment past the first byte, so it is read again
+		gb.haltBug = false
+		offset = 0
+	}
+
 	var opbytes OpBytes
 	var ok bool
 	var displacement, opcode byte
@@ -63,7 +343,7 @@ func (gb *GameBoy) RunInstruction() {
 
 	// check for known prefixes
 	if prefix == 0xCB {
-		opcode = gb.ReadRom8(gb.pc + offset)
+		opcode = gb.cpuRead(gb.pc + offset)
 		offset++
 
 		opbytes, ok = cb[opcode]
@@ -86,27 +366,41 @@ func (gb *GameBoy) RunInstruction() {
 
 	if opbytes.HasDisplacement {
 		// byte after opcode
-		displacement = gb.ReadRom8(gb.pc + offset)
+		displacement = gb.cpuRead(gb.pc + offset)
 		offset++
 	}
 
 	if opbytes.ImmediateSize == 1 {
-		immediate = uint16(gb.ReadRom8(gb.pc + offset))
+		immediate = uint16(gb.cpuRead(gb.pc + offset))
 	} else if opbytes.ImmediateSize == 2 {
-		immediate = gb.ReadRom16(gb.pc + offset)
+		lsb := gb.cpuRead(gb.pc + offset)
+		msb := gb.cpuRead(gb.pc + offset + 1)
+		immediate = mergeBytes(msb, lsb)
 	}
 
 	offset += uint16(opbytes.ImmediateSize)
 
 	// gb.debugPrintlnf("displacement: %.2X, immediate: %.4X", displacement, immediate)
 
+	// like the real CPU the PC points at the next instruction while this one
+	// executes, so jumps and calls don't need to account for its length
+	gb.pc += offset
+	gb.branched = false
+
 	if opbytes.Operation != nil {
 		opbytes.Operation(gb, prefix, OpCode(opcode), displacement, immediate)
 	} else if opcode != 0 {
-		gb.debugLnF("unknown opcode %.2X at PC %.4X", opcode, gb.pc)
+		gb.debugLnF("unknown opcode %.2X at PC %.4X", opcode, gb.pc-offset)
 	}
 
-	gb.pc += offset
+	cycles := opbytes.Cycles
+	if gb.branched {
+		cycles = opbytes.BranchCycles
+	}
+
+	for gb.tickCount-firstTick < uint64(cycles)*4 {
+		gb.tick()
+	}
 
 	gb.debugLnF("HL: %.4X", gb.readHL())
 	gb.debugLnF("next PC: %.4X", gb.pc)
@@ -0,0 +1,137 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestROM builds a ROM image with a valid header and room for a program at 0x0150
+func newTestROM(cartType uint8, romSizeCode uint8, ramSizeCode uint8) (rom []byte) {
+	rom = make([]byte, 0x8000<<romSizeCode)
+
+	copy(rom[0x0104:], nintendoLogo[:])
+	copy(rom[0x0134:], "GOBOY TEST")
+	rom[0x0147] = cartType
+	rom[0x0148] = romSizeCode
+	rom[0x0149] = ramSizeCode
+
+	// NOP; JP 0x0150
+	copy(rom[0x0100:], []byte{0x00, 0xC3, 0x50, 0x01})
+
+	fixChecksums(rom)
+
+	return rom
+}
+
+// fixChecksums recalculates both header checksums after a test has modified a ROM
+func fixChecksums(rom []byte) {
+	var hsum uint8
+	for i := 0x0134; i <= 0x014C; i++ {
+		hsum = hsum - rom[i] - 1
+	}
+
+	rom[0x014D] = hsum
+
+	var gsum uint16
+	for i, b := range rom {
+		if i != 0x014E && i != 0x014F {
+			gsum += uint16(b)
+		}
+	}
+
+	rom[0x014E], rom[0x014F] = splitBytes(gsum)
+}
+
+func TestParseCartridgeHeader(t *testing.T) {
+	rom := newTestROM(0x03, 0x01, 0x02)
+	rom[0x014B] = 0x33
+	copy(rom[0x0144:], "01")
+	rom[0x014C] = 0x02
+	fixChecksums(rom)
+
+	header, err := ParseCartridgeHeader(rom)
+	assert.NoError(t, err)
+	assert.NoError(t, header.Validate())
+
+	assert.Equal(t, "GOBOY TEST", header.Title)
+	assert.Equal(t, "", header.ManufacturerCode)
+	assert.Equal(t, "01", header.NewLicenseeCode)
+	assert.Equal(t, uint8(0x03), header.CartridgeType)
+	assert.Equal(t, uint8(0x02), header.Version)
+
+	romSize, err := header.ROMSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 0x10000, romSize)
+
+	ramSize, err := header.RAMSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 0x2000, ramSize)
+}
+
+func TestParseCartridgeHeaderCGB(t *testing.T) {
+	rom := newTestROM(0x00, 0x00, 0x00)
+	copy(rom[0x0134:], "POKEMON\x00\x00\x00\x00AAXE\xC0")
+
+	header, err := ParseCartridgeHeader(rom)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "POKEMON", header.Title)
+	assert.Equal(t, "AAXE", header.ManufacturerCode)
+	assert.Equal(t, uint8(0xC0), header.CGBFlag)
+}
+
+func TestCartridgeHeaderValidate(t *testing.T) {
+	rom := newTestROM(0x00, 0x00, 0x00)
+	rom[0x0110] ^= 0xFF
+	fixChecksums(rom)
+
+	header, err := ParseCartridgeHeader(rom)
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(header.Validate(), ErrLogo))
+
+	rom = newTestROM(0x00, 0x00, 0x00)
+	rom[0x014D]++
+
+	header, err = ParseCartridgeHeader(rom)
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(header.Validate(), ErrHeaderChecksum))
+
+	rom = newTestROM(0x00, 0x00, 0x00)
+	rom[0x4000]++
+
+	header, err = ParseCartridgeHeader(rom)
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(header.Validate(), ErrGlobalChecksum))
+}
+
+func TestLoadROMErrors(t *testing.T) {
+	gb := &GameBoy{}
+
+	err := gb.LoadROM(make([]byte, 0x0100))
+	assert.True(t, errors.Is(err, ErrTruncatedROM))
+
+	// declares 64KiB, only has 32KiB
+	rom := newTestROM(0x00, 0x01, 0x00)[:0x8000]
+	err = gb.LoadROM(rom)
+	assert.True(t, errors.Is(err, ErrTruncatedROM))
+
+	rom = newTestROM(0x00, 0x00, 0x00)
+	rom[0x0148] = 0x20
+	fixChecksums(rom)
+	err = gb.LoadROM(rom)
+	assert.True(t, errors.Is(err, ErrROMSize))
+
+	rom = newTestROM(0x00, 0x00, 0x00)
+	rom[0x0134] = 'X'
+	err = gb.LoadROM(rom)
+	assert.True(t, errors.Is(err, ErrHeaderChecksum))
+
+	// a failed load leaves nothing behind
+	assert.Nil(t, gb.Header())
+
+	err = gb.LoadROM(newTestROM(0x00, 0x00, 0x00))
+	assert.NoError(t, err)
+	assert.Equal(t, "GOBOY TEST", gb.Header().Title)
+}
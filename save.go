@@ -0,0 +1,100 @@
+package goboy
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// save errors
+var (
+	ErrNoBattery = errors.New("cartridge has no battery")
+	ErrSaveSize  = errors.New("save doesn't match the cartridge RAM size")
+)
+
+// LoadSaveRAM restores battery backed cartridge RAM from a .sav file. The
+// layout is the raw RAM, like other emulators use, followed by a 48 (or 44)
+// byte trailer for cartridges with a real-time clock. Saves without the
+// trailer leave the clock alone.
+func (gb *GameBoy) LoadSaveRAM(r io.Reader) (err error) {
+	if gb.header == nil || !gb.header.HasBattery() {
+		return ErrNoBattery
+	}
+
+	d, err := io.ReadAll(r)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	ram := gb.mbc.externalRAM()
+	if len(d) < len(ram) {
+		return errors.Wrapf(ErrSaveSize, "expected %d bytes, got %d", len(ram), len(d))
+	}
+
+	trailer := d[len(ram):]
+	clock := gb.cartridgeClock()
+
+	if len(trailer) != 0 {
+		if clock == nil {
+			return errors.Wrapf(ErrSaveSize, "expected %d bytes, got %d", len(ram), len(d))
+		}
+
+		err = clock.load(trailer)
+		if err != nil {
+			return err
+		}
+	}
+
+	copy(ram, d)
+
+	if _, ok := gb.mbc.(*mbc2); ok {
+		// only half bytes are stored, files from other emulators can have
+		// anything in the upper nibble
+		for i := range ram {
+			ram[i] &= 0x0F
+		}
+	}
+
+	gb.ramDirty = false
+
+	return nil
+}
+
+// SaveRAM writes battery backed cartridge RAM in the .sav layout LoadSaveRAM
+// reads, and clears RAMDirty
+func (gb *GameBoy) SaveRAM(w io.Writer) (err error) {
+	if gb.header == nil || !gb.header.HasBattery() {
+		return ErrNoBattery
+	}
+
+	_, err = w.Write(gb.mbc.externalRAM())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if clock := gb.cartridgeClock(); clock != nil {
+		err = clock.save(w)
+		if err != nil {
+			return err
+		}
+	}
+
+	gb.ramDirty = false
+
+	return nil
+}
+
+// RAMDirty is whether battery backed cartridge RAM or the clock has changed
+// since it was last loaded or saved, frontends can use it to only save when needed
+func (gb *GameBoy) RAMDirty() (dirty bool) {
+	return gb.ramDirty
+}
+
+// cartridgeClock is the cartridge's real-time clock, if it has one
+func (gb *GameBoy) cartridgeClock() (clock *rtc) {
+	if m, ok := gb.mbc.(*mbc3); ok {
+		return m.rtc
+	}
+
+	return nil
+}
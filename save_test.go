@@ -0,0 +1,95 @@
+package goboy
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveRAMRoundTrip(t *testing.T) {
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(newTestROM(0x03, 0x00, 0x02))) // MBC1+RAM+BATTERY
+	assert.False(t, gb.RAMDirty())
+
+	// writes with the RAM disabled don't reach it
+	gb.WriteMemory(0xA123, 0x45)
+	assert.False(t, gb.RAMDirty())
+
+	// nor do ones that store what's already there
+	gb.WriteMemory(0x0000, 0x0A)
+	gb.WriteMemory(0xA123, 0x00)
+	assert.False(t, gb.RAMDirty())
+
+	gb.WriteMemory(0xA123, 0x45)
+	assert.True(t, gb.RAMDirty())
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, gb.SaveRAM(buf))
+	assert.False(t, gb.RAMDirty())
+	assert.Equal(t, 0x2000, buf.Len())
+	assert.Equal(t, uint8(0x45), buf.Bytes()[0x0123])
+
+	other := &GameBoy{}
+	assert.NoError(t, other.LoadROM(newTestROM(0x03, 0x00, 0x02)))
+	assert.NoError(t, other.LoadSaveRAM(buf))
+
+	other.WriteMemory(0x0000, 0x0A)
+	assert.Equal(t, uint8(0x45), other.ReadMemory(0xA123))
+}
+
+func TestSaveRAMErrors(t *testing.T) {
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(newTestROM(0x02, 0x00, 0x02))) // MBC1+RAM, no battery
+
+	assert.True(t, errors.Is(gb.SaveRAM(&bytes.Buffer{}), ErrNoBattery))
+	assert.True(t, errors.Is(gb.LoadSaveRAM(&bytes.Buffer{}), ErrNoBattery))
+
+	assert.NoError(t, gb.LoadROM(newTestROM(0x03, 0x00, 0x02)))
+	assert.True(t, errors.Is(gb.LoadSaveRAM(bytes.NewReader(make([]byte, 0x1000))), ErrSaveSize))
+	assert.True(t, errors.Is(gb.LoadSaveRAM(bytes.NewReader(make([]byte, 0x2030))), ErrSaveSize))
+}
+
+func TestSaveRAMWithRTC(t *testing.T) {
+	clock := &fakeClock{time.Unix(1_600_000_000, 0)}
+	gb := newMBC3TestGameBoy(t, clock)
+
+	gb.WriteMemory(0x4000, 0x00)
+	gb.WriteMemory(0xA000, 0x99)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, gb.SaveRAM(buf))
+	assert.Equal(t, 0x8000+rtcTrailerSize, buf.Len())
+
+	clock.t = clock.t.Add(90 * time.Second)
+
+	other := newMBC3TestGameBoy(t, clock)
+	assert.NoError(t, other.LoadSaveRAM(buf))
+
+	other.WriteMemory(0x4000, 0x00)
+	assert.Equal(t, uint8(0x99), other.ReadMemory(0xA000))
+
+	latchRTC(other)
+	assert.Equal(t, uint8(30), readRTC(other, rtcS))
+	assert.Equal(t, uint8(1), readRTC(other, rtcM))
+
+	// saves from emulators without RTC support still load
+	assert.NoError(t, other.LoadSaveRAM(bytes.NewReader(make([]byte, 0x8000))))
+}
+
+func TestSaveRAMMBC2(t *testing.T) {
+	gb := &GameBoy{}
+	assert.NoError(t, gb.LoadROM(newTestROM(0x06, 0x00, 0x00))) // MBC2+BATTERY
+
+	save := bytes.Repeat([]byte{0xF7}, 0x0200)
+	assert.NoError(t, gb.LoadSaveRAM(bytes.NewReader(save)))
+
+	gb.WriteMemory(0x0000, 0x0A)
+	assert.Equal(t, uint8(0xF7), gb.ReadMemory(0xA000))
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, gb.SaveRAM(buf))
+	assert.Equal(t, bytes.Repeat([]byte{0x07}, 0x0200), buf.Bytes())
+}
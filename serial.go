@@ -0,0 +1,210 @@
+package goboy
+
+import "sync"
+
+// serialBit is the divider bit whose falling edge clocks the serial port, 8192Hz
+const serialBit = 1 << 8
+
+// SC bits
+const (
+	scInternalClock uint8 = 1 << 0 // this end drives the clock
+	scTransfer      uint8 = 1 << 7 // a transfer is requested or under way
+)
+
+// LinkCable is whatever is plugged into the serial port. Bytes go both ways
+// at once, the end with the internal clock starts each exchange.
+// see https://gbdev.io/pandocs/Serial_Data_Transfer_(Link_Cable).html
+type LinkCable interface {
+	// Transfer is called by the end with the internal clock as it starts
+	// shifting out a byte, it returns the byte shifted back in
+	Transfer(out uint8) (in uint8)
+
+	// Ready is called regularly by an end waiting on the external clock, with
+	// the byte it will shift out. It returns the byte the other end clocked in
+	// once there is one.
+	Ready(out uint8) (in uint8, ok bool)
+}
+
+// LinkCanceler is implemented by link cables that hold on to the byte offered
+// through Ready. The serial port calls Cancel when software stops waiting on
+// the external clock, so the other end's next Transfer doesn't take the stale
+// offer.
+type LinkCanceler interface {
+	Cancel()
+}
+
+// Disconnected is a link port with nothing plugged in. The data line floats
+// high and no clock ever arrives.
+type Disconnected struct{}
+
+// Transfer shifts in 0xFF
+func (Disconnected) Transfer(out uint8) (in uint8) {
+	return 0xFF
+}
+
+// Ready never completes
+func (Disconnected) Ready(out uint8) (in uint8, ok bool) {
+	return 0, false
+}
+
+// serial is the state behind SB and SC
+type serial struct {
+	sb   uint8 // Serial transfer data, the shift register
+	sc   uint8 // Serial transfer control, only bits 0 and 7 are stored
+	bits int   // bits left to shift in the transfer under way
+	in   uint8 // the byte being shifted in, most significant bit first
+}
+
+// link is Link, or Disconnected when nothing is plugged in
+func (gb *GameBoy) link() (link LinkCable) {
+	if gb.Link == nil {
+		return Disconnected{}
+	}
+
+	return gb.Link
+}
+
+// serialClock runs on every edge of the 8192Hz serial clock, shifting a bit
+// of a transfer under way or checking whether a waiting one can start
+func (gb *GameBoy) serialClock() {
+	s := &gb.serial
+
+	if clock, ok := gb.Link.(LinkClock); ok {
+		clock.Sync(gb.tickCount)
+	}
+
+	if s.sc&scTransfer == 0 {
+		return
+	}
+
+	if s.bits == 0 {
+		if s.sc&scInternalClock != 0 {
+			return
+		}
+
+		in, ok := gb.link().Ready(s.sb)
+		if !ok {
+			return
+		}
+
+		// the other end's clock runs at the same rate as ours
+		s.bits, s.in = 8, in
+
+		return
+	}
+
+	s.sb = s.sb<<1 | s.in>>7
+	s.in <<= 1
+	s.bits--
+
+	if s.bits == 0 {
+		s.sc &^= scTransfer
+		gb.requestInterrupt(interruptSerial)
+	}
+}
+
+// cancelSerial tells the link this end isn't waiting on the external clock
+// anymore
+func (gb *GameBoy) cancelSerial() {
+	if canceler, ok := gb.Link.(LinkCanceler); ok {
+		canceler.Cancel()
+	}
+}
+
+func (gb *GameBoy) readSerial(address uint16) (value byte) {
+	if address == SB {
+		return gb.serial.sb
+	}
+
+	// unused bits read as 1
+	return gb.serial.sc | 0x7E
+}
+
+func (gb *GameBoy) writeSerial(address uint16, value byte) {
+	s := &gb.serial
+
+	if address == SB {
+		s.sb = value
+		return
+	}
+
+	waiting := s.sc == scTransfer && s.bits == 0
+
+	s.sc = value & (scTransfer | scInternalClock)
+	s.bits = 0
+
+	if waiting && s.sc != scTransfer {
+		gb.cancelSerial()
+	}
+
+	if s.sc == scTransfer|scInternalClock {
+		s.bits, s.in = 8, gb.link().Transfer(s.sb)
+	}
+}
+
+// NewLinkCable returns both ends of a link cable for connecting two GameBoys
+// in the same process. The GameBoys can run on separate goroutines.
+func NewLinkCable() (a, b LinkCable) {
+	cable := &linkCable{}
+
+	return &linkEnd{cable, 0}, &linkEnd{cable, 1}
+}
+
+// linkCable is the shared state of both ends of a NewLinkCable
+type linkCable struct {
+	mu        sync.Mutex
+	waiting   [2]bool  // the end is waiting on the external clock
+	offered   [2]uint8 // the byte a waiting end will shift out
+	delivered [2]bool  // a byte was clocked into the end
+	received  [2]uint8 // the byte clocked into the end
+}
+
+// linkEnd is one end of a linkCable
+type linkEnd struct {
+	cable *linkCable
+	end   int
+}
+
+// Transfer takes the byte the other end offered if it is waiting, otherwise
+// nothing is listening and the line stays high
+func (l *linkEnd) Transfer(out uint8) (in uint8) {
+	c := l.cable
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	other := 1 - l.end
+	if !c.waiting[other] {
+		return 0xFF
+	}
+
+	c.waiting[other] = false
+	c.delivered[other], c.received[other] = true, out
+
+	return c.offered[other]
+}
+
+func (l *linkEnd) Ready(out uint8) (in uint8, ok bool) {
+	c := l.cable
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.delivered[l.end] {
+		c.delivered[l.end] = false
+		return c.received[l.end], true
+	}
+
+	c.waiting[l.end], c.offered[l.end] = true, out
+
+	return 0, false
+}
+
+// Cancel withdraws the offered byte, along with any the other end clocked in
+// that this end hasn't picked up
+func (l *linkEnd) Cancel() {
+	c := l.cable
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.waiting[l.end] = false
+	c.delivered[l.end] = false
+}
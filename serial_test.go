@@ -0,0 +1,87 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serialBitTicks are the M-cycles between serial clock edges
+const serialBitTicks = serialBit * 2 / 4
+
+func TestSerialDisconnected(t *testing.T) {
+	gb := &GameBoy{}
+
+	assert.Equal(t, uint8(0x7E), gb.ReadMemory(SC))
+
+	gb.WriteMemory(SB, 0x42)
+	gb.WriteMemory(SC, 0x81)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(SC))
+
+	// a bit per edge, the divider starts at 0 so they come every serialBitTicks
+	ticks(gb, serialBitTicks*4)
+	assert.Equal(t, uint8(0x2F), gb.ReadMemory(SB), "half shifted")
+
+	ticks(gb, serialBitTicks*3)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(SC))
+	assert.Zero(t, gb.ReadMemory(IF)&interruptSerial)
+
+	ticks(gb, serialBitTicks)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(SB), "floating line shifts in 1s")
+	assert.Equal(t, uint8(0x7F), gb.ReadMemory(SC))
+	assert.NotZero(t, gb.ReadMemory(IF)&interruptSerial)
+
+	// waiting on an external clock that never comes
+	gb.WriteMemory(SC, 0x80)
+	ticks(gb, serialBitTicks*100)
+	assert.Equal(t, uint8(0xFE), gb.ReadMemory(SC))
+}
+
+func TestLinkCable(t *testing.T) {
+	a, b := &GameBoy{}, &GameBoy{}
+	a.Link, b.Link = NewLinkCable()
+
+	// b waits for a's clock
+	b.WriteMemory(SB, 0x55)
+	b.WriteMemory(SC, 0x80)
+	ticks(b, serialBitTicks)
+
+	a.WriteMemory(SB, 0xAA)
+	a.WriteMemory(SC, 0x81)
+
+	for i := 0; i < 10; i++ {
+		ticks(a, serialBitTicks)
+		ticks(b, serialBitTicks)
+	}
+
+	assert.Equal(t, uint8(0x55), a.ReadMemory(SB))
+	assert.Equal(t, uint8(0xAA), b.ReadMemory(SB))
+	assert.Equal(t, uint8(0x7F), a.ReadMemory(SC))
+	assert.Equal(t, uint8(0x7E), b.ReadMemory(SC))
+	assert.NotZero(t, a.ReadMemory(IF)&interruptSerial)
+	assert.NotZero(t, b.ReadMemory(IF)&interruptSerial)
+
+	// with nobody waiting on the other end it's the same as no cable
+	a.WriteMemory(SB, 0x12)
+	a.WriteMemory(SC, 0x81)
+	ticks(a, serialBitTicks*9)
+	assert.Equal(t, uint8(0xFF), a.ReadMemory(SB))
+	assert.Equal(t, uint8(0xAA), b.ReadMemory(SB))
+
+	// b stops waiting, its offer is withdrawn
+	b.WriteMemory(SB, 0x34)
+	b.WriteMemory(SC, 0x80)
+	ticks(b, serialBitTicks)
+	b.WriteMemory(SC, 0x00)
+
+	a.WriteMemory(SB, 0x56)
+	a.WriteMemory(SC, 0x81)
+	ticks(a, serialBitTicks*9)
+	assert.Equal(t, uint8(0xFF), a.ReadMemory(SB))
+
+	// and nothing a clocked out is waiting for it when it starts again
+	b.WriteMemory(SC, 0x80)
+	ticks(b, serialBitTicks*9)
+	assert.Equal(t, uint8(0x34), b.ReadMemory(SB))
+	assert.Equal(t, uint8(0xFE), b.ReadMemory(SC))
+}
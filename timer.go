@@ -0,0 +1,113 @@
+package goboy
+
+// timer is the state behind DIV, TIMA, TMA and TAC. All four are driven by a
+// single 16 bit counter that goes up every T-cycle, DIV is its upper byte and
+// TIMA counts the falling edges of the bit TAC selects.
+// see https://gbdev.io/pandocs/Timer_Obscure_Behaviour.html
+type timer struct {
+	counter uint16 // internal divider, reset by writing to DIV
+	tima    uint8  // Timer Counter
+	tma     uint8  // Timer Modulo - reloaded into TIMA when it overflows
+	tac     uint8  // Timer Control - bit 2 enables TIMA, bits 0-1 select its rate
+
+	overflowed bool // TIMA overflowed last cycle and reads 0 until it is reloaded
+	reloaded   bool // TIMA was reloaded from TMA this cycle
+}
+
+// timerBits are the divider bits TIMA counts the falling edges of, by TAC's
+// clock select: 4096Hz, 262144Hz, 65536Hz and 16384Hz
+var timerBits = [4]uint16{1 << 9, 1 << 3, 1 << 5, 1 << 7}
+
+// timerSignal is the input to TIMA's falling edge detector, the selected
+// divider bit ANDed with the enable bit. Anything that drops it from 1 to 0
+// increments TIMA, including the DIV and TAC writes that real games trip over.
+func (gb *GameBoy) timerSignal() (high bool) {
+	return gb.timer.tac&0x04 != 0 && gb.timer.counter&timerBits[gb.timer.tac&0x03] != 0
+}
+
+// timerTick advances the timer by an M-cycle
+func (gb *GameBoy) timerTick() {
+	gb.timer.reloaded = false
+
+	if gb.timer.overflowed {
+		// a cycle late, TIMA is reloaded and the interrupt requested
+		gb.timer.overflowed = false
+		gb.timer.reloaded = true
+		gb.timer.tima = gb.timer.tma
+		gb.requestInterrupt(interruptTimer)
+	}
+
+	gb.setTimerCounter(gb.timer.counter + 4)
+}
+
+// setTimerCounter changes the divider, incrementing TIMA on a falling edge
+func (gb *GameBoy) setTimerCounter(counter uint16) {
+	before := gb.timerSignal()
+	sequencer := gb.timer.counter&sequencerBit != 0
+	serial := gb.timer.counter&serialBit != 0
+	gb.timer.counter = counter
+
+	if before && !gb.timerSignal() {
+		gb.incrementTIMA()
+	}
+
+	if sequencer && gb.timer.counter&sequencerBit == 0 {
+		gb.stepSequencer()
+	}
+
+	if serial && gb.timer.counter&serialBit == 0 {
+		gb.serialClock()
+	}
+}
+
+func (gb *GameBoy) incrementTIMA() {
+	gb.timer.tima++
+
+	if gb.timer.tima == 0 {
+		gb.timer.overflowed = true
+	}
+}
+
+func (gb *GameBoy) readTimer(address uint16) (value byte) {
+	switch address {
+	case DIV:
+		return uint8(gb.timer.counter >> 8)
+	case TIMA:
+		return gb.timer.tima
+	case TMA:
+		return gb.timer.tma
+	default: // TAC, unused bits read as 1
+		return gb.timer.tac | 0xF8
+	}
+}
+
+func (gb *GameBoy) writeTimer(address uint16, value byte) {
+	switch address {
+	case DIV:
+		// any write resets the whole divider
+		gb.setTimerCounter(0)
+	case TIMA:
+		if gb.timer.reloaded {
+			// TMA wins on the cycle it is reloaded
+			return
+		}
+
+		// writing during the overflow delay cancels the reload and interrupt
+		gb.timer.overflowed = false
+		gb.timer.tima = value
+	case TMA:
+		gb.timer.tma = value
+
+		if gb.timer.reloaded {
+			// the reload sees the new value
+			gb.timer.tima = value
+		}
+	case TAC:
+		before := gb.timerSignal()
+		gb.timer.tac = value & 0x07
+
+		if before && !gb.timerSignal() {
+			gb.incrementTIMA()
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ticks advances the GameBoy by n M-cycles without running the CPU
+func ticks(gb *GameBoy, n int) {
+	for i := 0; i < n; i++ {
+		gb.tick()
+	}
+}
+
+func TestDIV(t *testing.T) {
+	gb := &GameBoy{}
+
+	ticks(gb, 63)
+	assert.Equal(t, uint8(0), gb.ReadMemory(DIV))
+
+	ticks(gb, 1)
+	assert.Equal(t, uint8(1), gb.ReadMemory(DIV))
+
+	ticks(gb, 64*0xFF)
+	assert.Equal(t, uint8(0), gb.ReadMemory(DIV), "wraps around")
+
+	ticks(gb, 100)
+	gb.WriteMemory(DIV, 0x12)
+	assert.Equal(t, uint8(0), gb.ReadMemory(DIV))
+	assert.Equal(t, uint16(0), gb.timer.counter, "the whole divider is reset")
+}
+
+func TestTIMARates(t *testing.T) {
+	cases := []struct {
+		tac    uint8
+		period int // M-cycles per increment
+	}{
+		{0x04, 256},
+		{0x05, 4},
+		{0x06, 16},
+		{0x07, 64},
+	}
+
+	for _, tc := range cases {
+		gb := &GameBoy{}
+		gb.WriteMemory(TAC, tc.tac)
+		assert.Equal(t, tc.tac|0xF8, gb.ReadMemory(TAC))
+
+		ticks(gb, tc.period-1)
+		assert.Equal(t, uint8(0), gb.ReadMemory(TIMA), "TAC %.2X", tc.tac)
+
+		ticks(gb, 1)
+		assert.Equal(t, uint8(1), gb.ReadMemory(TIMA), "TAC %.2X", tc.tac)
+
+		ticks(gb, tc.period*9)
+		assert.Equal(t, uint8(10), gb.ReadMemory(TIMA), "TAC %.2X", tc.tac)
+	}
+
+	gb := &GameBoy{}
+	gb.WriteMemory(TAC, 0x01)
+	ticks(gb, 100)
+	assert.Equal(t, uint8(0), gb.ReadMemory(TIMA), "disabled")
+}
+
+func TestTIMAOverflow(t *testing.T) {
+	gb := &GameBoy{}
+	gb.WriteMemory(TMA, 0xAB)
+	gb.WriteMemory(TIMA, 0xFF)
+	gb.WriteMemory(TAC, 0x05)
+
+	ticks(gb, 4)
+	assert.Equal(t, uint8(0x00), gb.ReadMemory(TIMA), "reads 0 for a cycle")
+	assert.Equal(t, uint8(0), gb.io[IF-0xFF00]&interruptTimer)
+
+	ticks(gb, 1)
+	assert.Equal(t, uint8(0xAB), gb.ReadMemory(TIMA))
+	assert.Equal(t, interruptTimer, gb.io[IF-0xFF00]&interruptTimer)
+}
+
+func TestTIMAOverflowWrites(t *testing.T) {
+	overflow := func() (gb *GameBoy) {
+		gb = &GameBoy{}
+		gb.WriteMemory(TMA, 0xAB)
+		gb.WriteMemory(TIMA, 0xFF)
+		gb.WriteMemory(TAC, 0x05)
+		ticks(gb, 4)
+
+		return gb
+	}
+
+	// writing TIMA during the delay cancels the reload and the interrupt
+	gb := overflow()
+	gb.WriteMemory(TIMA, 0x42)
+	ticks(gb, 1)
+	assert.Equal(t, uint8(0x42), gb.ReadMemory(TIMA))
+	assert.Equal(t, uint8(0), gb.io[IF-0xFF00]&interruptTimer)
+
+	// writing TIMA as it is reloaded is ignored
+	gb = overflow()
+	ticks(gb, 1)
+	gb.WriteMemory(TIMA, 0x42)
+	assert.Equal(t, uint8(0xAB), gb.ReadMemory(TIMA))
+	assert.Equal(t, interruptTimer, gb.io[IF-0xFF00]&interruptTimer)
+
+	// writing TMA as TIMA is reloaded is seen by the reload
+	gb = overflow()
+	ticks(gb, 1)
+	gb.WriteMemory(TMA, 0x42)
+	assert.Equal(t, uint8(0x42), gb.ReadMemory(TIMA))
+
+	// but after that TIMA keeps counting
+	ticks(gb, 4)
+	gb.WriteMemory(TMA, 0x10)
+	assert.Equal(t, uint8(0x43), gb.ReadMemory(TIMA))
+}
+
+func TestTimerGlitches(t *testing.T) {
+	// resetting DIV while the selected bit is set is a falling edge
+	gb := &GameBoy{}
+	gb.WriteMemory(TAC, 0x05)
+	ticks(gb, 2)
+	gb.WriteMemory(DIV, 0)
+	assert.Equal(t, uint8(1), gb.ReadMemory(TIMA))
+
+	// while it is clear it isn't
+	gb = &GameBoy{}
+	gb.WriteMemory(TAC, 0x05)
+	ticks(gb, 1)
+	gb.WriteMemory(DIV, 0)
+	assert.Equal(t, uint8(0), gb.ReadMemory(TIMA))
+
+	// disabling the timer while the selected bit is set
+	gb = &GameBoy{}
+	gb.WriteMemory(TAC, 0x05)
+	ticks(gb, 2)
+	gb.WriteMemory(TAC, 0x01)
+	assert.Equal(t, uint8(1), gb.ReadMemory(TIMA))
+
+	// switching from a set bit to a clear one
+	gb = &GameBoy{}
+	gb.WriteMemory(TAC, 0x05)
+	ticks(gb, 2)
+	gb.WriteMemory(TAC, 0x06)
+	assert.Equal(t, uint8(1), gb.ReadMemory(TIMA))
+}
+
+func TestTimerInterrupt(t *testing.T) {
+	gb := newTestCPU(
+		0xFB,       // EI
+		0x18, 0xFE, // JR -2
+	)
+	gb.ie = interruptTimer
+	gb.WriteMemory(TIMA, 0xFE)
+	gb.WriteMemory(TAC, 0x05)
+
+	for i := 0; i < 10 && gb.pc != 0x0050; i++ {
+		gb.RunInstruction()
+	}
+
+	assert.Equal(t, uint16(0x0050), gb.pc)
+	assert.Equal(t, uint8(0), gb.io[IF-0xFF00]&interruptTimer)
+}
+
+func TestReadDIVMidInstruction(t *testing.T) {
+	gb := newTestCPU(
+		0xF0, 0x04, // LD A, (DIV)
+	)
+	gb.timer.counter = 0x00F4
+
+	// DIV is read on the third M-cycle, after it ticked over
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x01), gb.a)
+
+	gb = newTestCPU(
+		0xF0, 0x04, // LD A, (DIV)
+	)
+	gb.timer.counter = 0x00F0
+
+	gb.RunInstruction()
+	assert.Equal(t, uint8(0x00), gb.a)
+}
@@ -1,5 +1,7 @@
 package goboy
 
+import "image"
+
 // Screen dimensions
 const (
 	ScreenWidth  = 160
@@ -15,9 +17,266 @@ const (
 	White     = 0xFFFFFFFF
 )
 
-// VRAM is an 8x8 set of tiles, memory mapped?
-var VRAM [8][8]uint8
+// PPU timings, in dots which are the same as T-cycles on the DMG
+const (
+	dotsPerLine   = 456
+	linesPerFrame = 154
+	oamScanDots   = 80  // length of mode 2
+	drawingDots   = 172 // shortest length of mode 3, fine scrolling makes it longer
+)
+
+// PPU modes, as reported in the lower 2 bits of STAT
+const (
+	modeHBlank uint8 = iota
+	modeVBlank
+	modeOAMScan
+	modeDrawing
+)
+
+// LCDC bits
+const (
+	lcdcBGEnable      uint8 = 1 << iota // background and window enable
+	lcdcOBJEnable                       // sprite enable
+	lcdcOBJSize                         // 8x16 sprites instead of 8x8
+	lcdcBGTileMap                       // background uses the 0x9C00 tile map instead of 0x9800
+	lcdcTileData                        // tiles at 0x8000 unsigned instead of 0x9000 signed
+	lcdcWindowEnable                    // window enable
+	lcdcWindowTileMap                   // window uses the 0x9C00 tile map instead of 0x9800
+	lcdcEnable                          // LCD and PPU enable
+)
+
+// STAT interrupt selects, the upper half of STAT
+const (
+	statHBlank  uint8 = 1 << (iota + 3) // mode 0
+	statVBlank                          // mode 1
+	statOAMScan                         // mode 2
+	statLYC                             // LY == LYC
+)
+
+// ppu is the state of the Pixel Processing Unit and its registers
+// see https://gbdev.io/pandocs/Rendering.html
+type ppu struct {
+	lcdc uint8 // LCD Control
+	stat uint8 // LCD Status, only the interrupt selects are stored
+	scy  uint8 // background scroll Y
+	scx  uint8 // background scroll X
+	lyc  uint8 // LY Compare
+	bgp  uint8 // background palette
+	obp0 uint8 // sprite palette 0
+	obp1 uint8 // sprite palette 1
+	wy   uint8 // window Y
+	wx   uint8 // window X + 7
+
+	line          uint8 // the line being drawn, LY reads the same apart from the end of line 153
+	dot           int   // dots into the current line
+	mode          uint8 // see the mode constants
+	drawingLength int   // dots mode 3 takes on the current line
+	coincidence   bool  // LY == LYC, as of the last M-cycle
+	statLine      bool  // OR of the enabled STAT sources, interrupts only fire on its rising edge
+
+	windowLine      uint8 // the window's own line counter, see renderWindow
+	windowTriggered bool  // LY matched WY this frame
+
+	fifo fifo // pixel pipeline, only used with AccuratePPU
+
+	back   [ScreenWidth * ScreenHeight]uint8 // shades of the frame being drawn
+	front  [ScreenWidth * ScreenHeight]uint8 // shades of the last complete frame
+	hidden bool                              // the frame being drawn is the first since the LCD was turned on
+	image  *image.RGBA                       // reused by Frame
+}
+
+// ly is the value of the LY register. Line 153 only reads as 153 for its first
+// M-cycle, after that it already reads as 0.
+func (gb *GameBoy) ly() (ly uint8) {
+	if gb.ppu.line == linesPerFrame-1 && gb.ppu.dot >= 4 {
+		return 0
+	}
+
+	return gb.ppu.line
+}
+
+// ppuTick advances the PPU by an M-cycle
+func (gb *GameBoy) ppuTick() {
+	if gb.ppu.lcdc&lcdcEnable == 0 {
+		return
+	}
+
+	for i := 0; i < 4; i++ {
+		gb.ppuDot()
+	}
+
+	gb.updateSTAT()
+}
+
+// ppuDot advances the PPU by a single dot, moving between modes
+func (gb *GameBoy) ppuDot() {
+	p := &gb.ppu
+
+	p.dot++
+	if p.dot == dotsPerLine {
+		p.dot = 0
+		p.line++
+
+		if p.line == linesPerFrame {
+			p.line = 0
+		}
+	}
+
+	switch {
+	case p.line >= ScreenHeight:
+		if p.line == ScreenHeight && p.dot == 0 {
+			p.mode = modeVBlank
+			gb.requestInterrupt(interruptVBlank)
+			gb.presentFrame()
+
+			p.windowLine, p.windowTriggered = 0, false
+
+			// the natural end of a frame for RunFrame
+			gb.frameDone = true
+		}
+	case p.dot == 0:
+		p.mode = modeOAMScan
+	case p.dot == oamScanDots:
+		p.mode = modeDrawing
+
+		if gb.AccuratePPU {
+			gb.startFIFO()
+			gb.fifoDot()
+		} else {
+			p.fifo.active = false
+			p.drawingLength = drawingDots + int(p.scx&0x07)
+			gb.renderLine()
+		}
+	case p.mode == modeDrawing && p.fifo.active:
+		if gb.fifoDot() {
+			p.mode = modeHBlank
+		}
+	case p.mode == modeDrawing && p.dot == oamScanDots+p.drawingLength:
+		p.mode = modeHBlank
+	}
+}
+
+// updateSTAT refreshes the LY == LYC flag and requests a STAT interrupt if any
+// enabled source became active while none were before. A source that stays
+// active blocks the others, which is how hardware behaves.
+func (gb *GameBoy) updateSTAT() {
+	p := &gb.ppu
+
+	p.coincidence = gb.ly() == p.lyc
+
+	line := p.stat&statLYC != 0 && p.coincidence
+	switch p.mode {
+	case modeHBlank:
+		line = line || p.stat&statHBlank != 0
+	case modeVBlank:
+		line = line || p.stat&statVBlank != 0
+
+		// the OAM select also fires as VBlank starts
+		if p.line == ScreenHeight && p.dot == 0 {
+			line = line || p.stat&statOAMScan != 0
+		}
+	case modeOAMScan:
+		line = line || p.stat&statOAMScan != 0
+	}
+
+	if line && !p.statLine {
+		gb.requestInterrupt(interruptSTAT)
+	}
+
+	p.statLine = line
+}
+
+// vramBlocked is true while the PPU is reading VRAM, when the CPU can't access it
+func (gb *GameBoy) vramBlocked() (blocked bool) {
+	return gb.ppu.mode == modeDrawing
+}
+
+// oamBlocked is true while the PPU is reading OAM, when the CPU can't access it
+func (gb *GameBoy) oamBlocked() (blocked bool) {
+	return gb.ppu.mode == modeOAMScan || gb.ppu.mode == modeDrawing
+}
+
+func (gb *GameBoy) readPPU(address uint16) (value byte) {
+	p := &gb.ppu
+
+	switch address {
+	case LCDC:
+		return p.lcdc
+	case STAT:
+		value = 0x80 | p.stat | p.mode
+		if p.coincidence {
+			value |= 0x04
+		}
+
+		return value
+	case SCY:
+		return p.scy
+	case SCX:
+		return p.scx
+	case LY:
+		if p.lcdc&lcdcEnable == 0 {
+			return 0
+		}
+
+		return gb.ly()
+	case LYC:
+		return p.lyc
+	case BGP:
+		return p.bgp
+	case OBP0:
+		return p.obp0
+	case OBP1:
+		return p.obp1
+	case WY:
+		return p.wy
+	default: // WX
+		return p.wx
+	}
+}
+
+func (gb *GameBoy) writePPU(address uint16, value byte) {
+	p := &gb.ppu
+
+	switch address {
+	case LCDC:
+		on := value&lcdcEnable != 0
+		wasOn := p.lcdc&lcdcEnable != 0
+		p.lcdc = value
+
+		if on != wasOn {
+			// either way the PPU starts over from the top. When it is turned
+			// on the first line skips OAM scan, staying in mode 0 until it
+			// starts drawing.
+			p.line, p.dot, p.mode = 0, 0, modeHBlank
+			p.statLine = false
+			p.coincidence = p.lyc == 0
+			p.windowLine, p.windowTriggered = 0, false
 
-// memory mapped?
-var scrollX uint8
-var scrollY uint8
+			// the screen is blank while it is off, and stays blank for the
+			// first frame after it is turned back on
+			p.front = [len(p.front)]uint8{}
+			p.hidden = on
+		}
+	case STAT:
+		// the lower 3 bits are read only
+		p.stat = value & 0x78
+	case SCY:
+		p.scy = value
+	case SCX:
+		p.scx = value
+	case LY:
+		// read only
+	case LYC:
+		p.lyc = value
+	case BGP:
+		p.bgp = value
+	case OBP0:
+		p.obp0 = value
+	case OBP1:
+		p.obp1 = value
+	case WY:
+		p.wy = value
+	case WX:
+		p.wx = value
+	}
+}
@@ -0,0 +1,152 @@
+package goboy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestPPU returns a GameBoy that just turned the LCD on
+func newTestPPU() (gb *GameBoy) {
+	gb = &GameBoy{}
+	gb.WriteMemory(LCDC, lcdcEnable|lcdcBGEnable)
+
+	return gb
+}
+
+func TestPPUModes(t *testing.T) {
+	gb := newTestPPU()
+
+	// the first line after turning the LCD on skips OAM scan
+	ticks(gb, 19)
+	assert.Equal(t, modeHBlank, gb.ppu.mode)
+	ticks(gb, 1)
+	assert.Equal(t, modeDrawing, gb.ppu.mode)
+
+	ticks(gb, 42)
+	assert.Equal(t, modeDrawing, gb.ppu.mode)
+	ticks(gb, 1)
+	assert.Equal(t, modeHBlank, gb.ppu.mode)
+	assert.Equal(t, uint8(0x84), gb.ReadMemory(STAT), "LY matches LYC")
+
+	ticks(gb, 114-63)
+	assert.Equal(t, uint8(1), gb.ReadMemory(LY))
+	assert.Equal(t, modeOAMScan, gb.ppu.mode)
+	assert.Equal(t, uint8(0x82), gb.ReadMemory(STAT))
+
+	ticks(gb, 20)
+	assert.Equal(t, modeDrawing, gb.ppu.mode)
+	assert.Equal(t, uint8(0x83), gb.ReadMemory(STAT))
+
+	// a line is 114 M-cycles, VBlank starts on line 144
+	ticks(gb, 114*143-20-1)
+	assert.Equal(t, uint8(143), gb.ReadMemory(LY))
+	assert.Equal(t, uint8(0), gb.io[IF-0xFF00]&interruptVBlank)
+
+	ticks(gb, 1)
+	assert.Equal(t, uint8(144), gb.ReadMemory(LY))
+	assert.Equal(t, modeVBlank, gb.ppu.mode)
+	assert.Equal(t, interruptVBlank, gb.io[IF-0xFF00]&interruptVBlank)
+	assert.True(t, gb.frameDone)
+}
+
+func TestPPUFineScroll(t *testing.T) {
+	gb := newTestPPU()
+	gb.WriteMemory(SCX, 0x05)
+
+	// 172 + 5 dots of drawing from dot 80 end at dot 257
+	ticks(gb, 64)
+	assert.Equal(t, modeDrawing, gb.ppu.mode)
+	ticks(gb, 1)
+	assert.Equal(t, modeHBlank, gb.ppu.mode)
+}
+
+func TestPPULine153(t *testing.T) {
+	gb := newTestPPU()
+
+	ticks(gb, 114*153)
+	assert.Equal(t, uint8(153), gb.ReadMemory(LY))
+
+	// LY already reads 0 for most of line 153
+	ticks(gb, 1)
+	assert.Equal(t, uint8(0), gb.ReadMemory(LY))
+	assert.Equal(t, modeVBlank, gb.ppu.mode)
+	assert.Equal(t, uint8(0x85), gb.ReadMemory(STAT))
+
+	ticks(gb, 113)
+	assert.Equal(t, uint8(0), gb.ReadMemory(LY))
+	assert.Equal(t, modeOAMScan, gb.ppu.mode)
+	assert.Equal(t, uint8(0x86), gb.ReadMemory(STAT))
+}
+
+func TestSTATInterrupt(t *testing.T) {
+	gb := newTestPPU()
+	gb.WriteMemory(STAT, statHBlank|statLYC)
+	gb.WriteMemory(LYC, 0)
+
+	ticks(gb, 1)
+	assert.Equal(t, interruptSTAT, gb.io[IF-0xFF00]&interruptSTAT, "LY == LYC")
+	gb.WriteMemory(IF, 0)
+
+	// still matching LYC during HBlank blocks the HBlank interrupt
+	ticks(gb, 113)
+	assert.Equal(t, uint8(0), gb.io[IF-0xFF00]&interruptSTAT)
+
+	// on the next line it isn't blocked
+	ticks(gb, 62)
+	assert.Equal(t, uint8(0), gb.io[IF-0xFF00]&interruptSTAT)
+	ticks(gb, 1)
+	assert.Equal(t, interruptSTAT, gb.io[IF-0xFF00]&interruptSTAT, "HBlank")
+
+	// the OAM select also fires at the start of VBlank
+	gb = newTestPPU()
+	gb.WriteMemory(STAT, statOAMScan)
+	ticks(gb, 114*144-1)
+	gb.WriteMemory(IF, 0)
+	ticks(gb, 1)
+	assert.Equal(t, interruptSTAT, gb.io[IF-0xFF00]&interruptSTAT)
+
+	ticks(gb, 114*10-1)
+	gb.WriteMemory(IF, 0)
+	ticks(gb, 1)
+	assert.Equal(t, interruptSTAT, gb.io[IF-0xFF00]&interruptSTAT, "line 0 OAM scan")
+}
+
+func TestVRAMAndOAMAccess(t *testing.T) {
+	gb := newTestPPU()
+	gb.WriteMemory(0x8000, 0x11)
+	gb.WriteMemory(0xFE00, 0x22)
+
+	// drawing, neither is accessible
+	ticks(gb, 20)
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0x8000))
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0xFE00))
+	gb.WriteMemory(0x8000, 0x33)
+	gb.WriteMemory(0xFE00, 0x44)
+
+	// HBlank, both are
+	ticks(gb, 43)
+	assert.Equal(t, uint8(0x11), gb.ReadMemory(0x8000))
+	assert.Equal(t, uint8(0x22), gb.ReadMemory(0xFE00))
+
+	// OAM scan, only VRAM is
+	ticks(gb, 51)
+	assert.Equal(t, uint8(0x11), gb.ReadMemory(0x8000))
+	assert.Equal(t, uint8(0xFF), gb.ReadMemory(0xFE00))
+}
+
+func TestLCDOff(t *testing.T) {
+	gb := newTestPPU()
+	ticks(gb, 114*10+30)
+
+	gb.WriteMemory(LCDC, 0)
+	assert.Equal(t, uint8(0), gb.ReadMemory(LY))
+	assert.Equal(t, uint8(0x80), gb.ReadMemory(STAT)&0x83)
+
+	// time doesn't pass for it while it is off
+	ticks(gb, 1000)
+	assert.Equal(t, uint8(0), gb.ReadMemory(LY))
+
+	gb.WriteMemory(0x8000, 0x55)
+	assert.Equal(t, uint8(0x55), gb.ReadMemory(0x8000))
+}